@@ -0,0 +1,297 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/philipreese/chirpy-go/internal/auth"
+	"github.com/philipreese/chirpy-go/internal/database"
+	"github.com/philipreese/chirpy-go/internal/pagination"
+)
+
+type BlockedUser struct {
+	Profile
+	BlockedAt time.Time `json:"blocked_at"`
+}
+
+type MutedUser struct {
+	Profile
+	MutedAt time.Time `json:"muted_at"`
+}
+
+// handlerBlockUser blocks a user and drops any follows between the two of
+// them. Blocks work both ways: neither side sees the other's chirps, or can
+// follow, reply to or quote them.
+func (cfg *apiConfig) handlerBlockUser(writer http.ResponseWriter, req *http.Request) {
+	blockedID, err := uuid.Parse(req.PathValue("userID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid user ID: " + err.Error())
+		return
+	}
+
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't get bearer token: " + err.Error())
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	if blockedID == userID {
+		respondWithError(writer, http.StatusBadRequest, "Can't block yourself")
+		return
+	}
+
+	if _, err := cfg.db.GetUserByID(req.Context(), blockedID); err != nil {
+		respondWithError(writer, http.StatusNotFound, "Couldn't get user")
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't start transaction: " + err.Error())
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	// a follow between the two checks for a block under the same lock, so
+	// it can't slip in after the follows below are removed
+	err = qtx.LockUserPair(req.Context(), database.LockUserPairParams{
+		UserID: userID,
+		OtherUserID: blockedID,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't block user: " + err.Error())
+		return
+	}
+
+	err = qtx.BlockUser(req.Context(), database.BlockUserParams{
+		BlockerID: userID,
+		BlockedID: blockedID,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't block user: " + err.Error())
+		return
+	}
+
+	err = qtx.DeleteFollowsBetween(req.Context(), database.DeleteFollowsBetweenParams{
+		UserID: userID,
+		OtherUserID: blockedID,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't remove follows: " + err.Error())
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't block user: " + err.Error())
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerUnblockUser(writer http.ResponseWriter, req *http.Request) {
+	blockedID, err := uuid.Parse(req.PathValue("userID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid user ID: " + err.Error())
+		return
+	}
+
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't get bearer token: " + err.Error())
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	err = cfg.db.UnblockUser(req.Context(), database.UnblockUserParams{
+		BlockerID: userID,
+		BlockedID: blockedID,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't unblock user: " + err.Error())
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerGetBlockedUsers(writer http.ResponseWriter, req *http.Request) {
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't get bearer token: " + err.Error())
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	page, err := pagination.ParseForwardParams(req.URL.Query())
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid pagination parameters: " + err.Error())
+		return
+	}
+
+	rows, err := cfg.db.GetBlockedUsers(req.Context(), database.GetBlockedUsersParams{
+		UserID: userID,
+		CursorCreatedAt: page.Cursor.NullTime(),
+		CursorID: page.Cursor.NullID(),
+		Limit: page.Limit + 1,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't retrieve blocked users: " + err.Error())
+		return
+	}
+
+	rows, next, _ := pagination.Page(rows, page.Limit, page.Cursor, func(row database.GetBlockedUsersRow) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: row.BlockedAt, ID: row.User.ID}
+	})
+	if link := pagination.LinkHeader(req.URL, next, ""); link != "" {
+		writer.Header().Set("Link", link)
+	}
+
+	blocked := []BlockedUser{}
+	for _, row := range rows {
+		blocked = append(blocked, BlockedUser{
+			Profile: databaseUserToProfile(row.User),
+			BlockedAt: row.BlockedAt,
+		})
+	}
+
+	respondWithJSON(writer, http.StatusOK, blocked)
+}
+
+func (cfg *apiConfig) handlerMuteUser(writer http.ResponseWriter, req *http.Request) {
+	mutedID, err := uuid.Parse(req.PathValue("userID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid user ID: " + err.Error())
+		return
+	}
+
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't get bearer token: " + err.Error())
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	if mutedID == userID {
+		respondWithError(writer, http.StatusBadRequest, "Can't mute yourself")
+		return
+	}
+
+	if _, err := cfg.db.GetUserByID(req.Context(), mutedID); err != nil {
+		respondWithError(writer, http.StatusNotFound, "Couldn't get user")
+		return
+	}
+
+	err = cfg.db.MuteUser(req.Context(), database.MuteUserParams{
+		MuterID: userID,
+		MutedID: mutedID,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't mute user: " + err.Error())
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerUnmuteUser(writer http.ResponseWriter, req *http.Request) {
+	mutedID, err := uuid.Parse(req.PathValue("userID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid user ID: " + err.Error())
+		return
+	}
+
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't get bearer token: " + err.Error())
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	err = cfg.db.UnmuteUser(req.Context(), database.UnmuteUserParams{
+		MuterID: userID,
+		MutedID: mutedID,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't unmute user: " + err.Error())
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerGetMutedUsers(writer http.ResponseWriter, req *http.Request) {
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't get bearer token: " + err.Error())
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	page, err := pagination.ParseForwardParams(req.URL.Query())
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid pagination parameters: " + err.Error())
+		return
+	}
+
+	rows, err := cfg.db.GetMutedUsers(req.Context(), database.GetMutedUsersParams{
+		UserID: userID,
+		CursorCreatedAt: page.Cursor.NullTime(),
+		CursorID: page.Cursor.NullID(),
+		Limit: page.Limit + 1,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't retrieve muted users: " + err.Error())
+		return
+	}
+
+	rows, next, _ := pagination.Page(rows, page.Limit, page.Cursor, func(row database.GetMutedUsersRow) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: row.MutedAt, ID: row.User.ID}
+	})
+	if link := pagination.LinkHeader(req.URL, next, ""); link != "" {
+		writer.Header().Set("Link", link)
+	}
+
+	muted := []MutedUser{}
+	for _, row := range rows {
+		muted = append(muted, MutedUser{
+			Profile: databaseUserToProfile(row.User),
+			MutedAt: row.MutedAt,
+		})
+	}
+
+	respondWithJSON(writer, http.StatusOK, muted)
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/philipreese/chirpy-go/internal/auth"
+	"github.com/philipreese/chirpy-go/internal/database"
+)
+
+type ChirpRevision struct {
+	ID           uuid.UUID `json:"id"`
+	ChirpID      uuid.UUID `json:"chirp_id"`
+	Body         string    `json:"body"`
+	BodyWithheld bool      `json:"body_withheld,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	ReplacedAt   time.Time `json:"replaced_at"`
+}
+
+func (cfg *apiConfig) handlerUpdateChirp(writer http.ResponseWriter, req *http.Request) {
+	type chirpRequest struct {
+		Body string `json:"body"`
+	}
+
+	chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid chirp ID: " + err.Error())
+		return
+	}
+
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't get bearer token: " + err.Error())
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	var chirpReq chirpRequest
+	if err := decoder.Decode(&chirpReq); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't decode parameters: " + err.Error())
+		return
+	}
+
+	cleanedBody, held, err := cfg.validateChirpBody(chirpReq.Body)
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid chirp: " + err.Error())
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't start transaction: " + err.Error())
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	dbChirp, err := qtx.GetChirpByIDForUpdate(req.Context(), chirpID)
+	if err != nil || dbChirp.TombstonedAt.Valid {
+		respondWithError(writer, http.StatusNotFound, "Couldn't get chirp")
+		return
+	}
+
+	if dbChirp.UserID != userID {
+		respondNotAuthor(writer, req, qtx, dbChirp, userID, "Not authorized to edit chirp")
+		return
+	}
+
+	// checked up front so resending the same body after the window has
+	// closed is refused like any other edit, not accepted as a no-op
+	if time.Now().UTC().Sub(dbChirp.CreatedAt) >= cfg.chirpEditWindow {
+		respondWithError(writer, http.StatusForbidden, "Chirp can no longer be edited")
+		return
+	}
+
+	if dbChirp.Body != cleanedBody {
+		spamHeld, ok := cfg.screenSpam(writer, req, qtx, userID, cleanedBody, uuid.NullUUID{UUID: dbChirp.ID, Valid: true})
+		if !ok {
+			return
+		}
+
+		dbChirp, err = updateChirpBody(req.Context(), qtx, dbChirp, cleanedBody, held || spamHeld, cfg.chirpEditWindow)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				respondWithError(writer, http.StatusForbidden, "Chirp can no longer be edited")
+				return
+			}
+			respondWithError(writer, http.StatusInternalServerError, "Couldn't update chirp: " + err.Error())
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't update chirp: " + err.Error())
+		return
+	}
+
+	chirp, err := cfg.buildChirp(req.Context(), dbChirp, uuid.NullUUID{UUID: userID, Valid: true})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't load chirp: " + err.Error())
+		return
+	}
+
+	respondWithJSON(writer, http.StatusOK, chirp)
+}
+
+// respondNotAuthor refuses a change to someone else's chirp. Callers who
+// can't see the chirp get the same 404 as if it didn't exist, so that the
+// refusal doesn't give away that it does.
+func respondNotAuthor(writer http.ResponseWriter, req *http.Request, q *database.Queries, dbChirp database.Chirp, userID uuid.UUID, msg string) {
+	_, err := q.GetChirpByID(req.Context(), database.GetChirpByIDParams{
+		ID: dbChirp.ID,
+		ViewerID: uuid.NullUUID{UUID: userID, Valid: true},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(writer, http.StatusNotFound, "Couldn't get chirp")
+		return
+	}
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't get chirp: " + err.Error())
+		return
+	}
+
+	respondWithError(writer, http.StatusForbidden, msg)
+}
+
+// updateChirpBody saves the current body of a chirp as a revision and then
+// replaces it, retagging the chirp to match. A held edit holds the chirp,
+// but an edit never releases one that is already held. sql.ErrNoRows means
+// the edit window has closed.
+func updateChirpBody(ctx context.Context, q *database.Queries, dbChirp database.Chirp, body string, held bool, editWindow time.Duration) (database.Chirp, error) {
+	_, err := q.CreateChirpRevision(ctx, database.CreateChirpRevisionParams{
+		ChirpID: dbChirp.ID,
+		Body: dbChirp.Body,
+		CreatedAt: dbChirp.UpdatedAt,
+	})
+	if err != nil {
+		return database.Chirp{}, err
+	}
+
+	updated, err := q.UpdateChirpBody(ctx, database.UpdateChirpBodyParams{
+		Body: body,
+		HeldForReview: held,
+		ID: dbChirp.ID,
+		EditWindowSeconds: editWindow.Seconds(),
+	})
+	if err != nil {
+		return database.Chirp{}, err
+	}
+
+	if err := q.DeleteChirpTags(ctx, updated.ID); err != nil {
+		return database.Chirp{}, err
+	}
+	if err := tagChirp(ctx, q, updated); err != nil {
+		return database.Chirp{}, err
+	}
+	if err := saveFingerprint(ctx, q, updated); err != nil {
+		return database.Chirp{}, err
+	}
+
+	return updated, nil
+}
+
+func (cfg *apiConfig) handlerGetChirpRevisions(writer http.ResponseWriter, req *http.Request) {
+	chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid chirp ID: " + err.Error())
+		return
+	}
+
+	viewerID, err := cfg.getViewerID(req)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	dbChirp, err := cfg.db.GetChirpByID(req.Context(), database.GetChirpByIDParams{
+		ID: chirpID,
+		ViewerID: viewerID,
+	})
+	if err != nil || dbChirp.TombstonedAt.Valid {
+		respondWithError(writer, http.StatusNotFound, "Couldn't get chirp")
+		return
+	}
+
+	// earlier bodies are withheld whenever the current one would be, and
+	// revealed the same way
+	withheld := false
+	if req.URL.Query().Get("reveal") != "true" {
+		chirps := []Chirp{databaseChirpToChirp(dbChirp)}
+		if err := cfg.withholdSensitive(req.Context(), chirps, viewerID); err != nil {
+			respondWithError(writer, http.StatusInternalServerError, "Couldn't load chirp: " + err.Error())
+			return
+		}
+		withheld = chirps[0].BodyWithheld
+	}
+
+	dbRevisions, err := cfg.db.GetChirpRevisions(req.Context(), chirpID)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't retrieve chirp revisions: " + err.Error())
+		return
+	}
+
+	revisions := []ChirpRevision{}
+	for _, dbRevision := range dbRevisions {
+		revision := ChirpRevision{
+			ID: dbRevision.ID,
+			ChirpID: dbRevision.ChirpID,
+			Body: dbRevision.Body,
+			CreatedAt: dbRevision.CreatedAt,
+			ReplacedAt: dbRevision.ReplacedAt,
+		}
+		if withheld {
+			revision.Body = ""
+			revision.BodyWithheld = true
+		}
+		revisions = append(revisions, revision)
+	}
+
+	respondWithJSON(writer, http.StatusOK, revisions)
+}
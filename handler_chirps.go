@@ -1,28 +1,67 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
-	"sort"
-	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/philipreese/chirpy-go/internal/auth"
 	"github.com/philipreese/chirpy-go/internal/database"
+	"github.com/philipreese/chirpy-go/internal/hashtags"
+	"github.com/philipreese/chirpy-go/internal/pagination"
+)
+
+// Chirp visibility levels. Public chirps appear everywhere, unlisted ones
+// are left out of every list but can still be opened by ID, and private
+// ones can only be seen by their author.
+const (
+	VisibilityPublic   = "public"
+	VisibilityUnlisted = "unlisted"
+	VisibilityPrivate  = "private"
 )
 
 type Chirp struct {
-	ID        uuid.UUID `json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Body      string    `json:"body"`
-	UserID    uuid.UUID `json:"user_id"`
+	ID             uuid.UUID         `json:"id"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+	Body           string            `json:"body"`
+	UserID         uuid.UUID         `json:"user_id"`
+	Visibility     string            `json:"visibility"`
+	ContentWarning string            `json:"content_warning"`
+	Sensitive      bool              `json:"sensitive"`
+	BodyWithheld   bool              `json:"body_withheld,omitempty"`
+	HeldForReview  bool              `json:"held_for_review,omitempty"`
+	InReplyTo      uuid.NullUUID     `json:"in_reply_to"`
+	QuotedChirpID  uuid.NullUUID     `json:"quoted_chirp_id"`
+	QuotedChirp    *Chirp            `json:"quoted_chirp"`
+	ReplyCount     int64             `json:"reply_count"`
+	RechirpCount   int64             `json:"rechirp_count"`
+	RechirpedBy    *uuid.UUID        `json:"rechirped_by,omitempty"`
+	RechirpedAt    *time.Time        `json:"rechirped_at,omitempty"`
+	LikeCount      int64             `json:"like_count"`
+	LikedByMe      *bool             `json:"liked_by_me,omitempty"`
+	Media          []MediaAttachment `json:"media"`
+	Poll           *Poll             `json:"poll"`
+	Deleted        bool              `json:"deleted,omitempty"`
+	DeletedAt      *time.Time        `json:"deleted_at,omitempty"`
 }
 
 func (cfg *apiConfig) handlerCreateChirp(writer http.ResponseWriter, req *http.Request) {
 	type chirpRequest struct {
-		Body   string    `json:"body"`
+		Body           string        `json:"body"`
+		InReplyTo      uuid.NullUUID `json:"in_reply_to"`
+		QuotedChirpID  uuid.NullUUID `json:"quoted_chirp_id"`
+		MediaIDs       []uuid.UUID   `json:"media_ids"`
+		PublishAt      *time.Time    `json:"publish_at"`
+		Visibility     string        `json:"visibility"`
+		ContentWarning string        `json:"content_warning"`
+		Sensitive      bool          `json:"sensitive"`
+		Poll           *pollRequest  `json:"poll"`
 	}
 
 	tokenString, err := auth.GetBearerToken(req.Header)
@@ -44,81 +83,273 @@ func (cfg *apiConfig) handlerCreateChirp(writer http.ResponseWriter, req *http.R
 		return
 	}
 
-	if len(chirpReq.Body) > 400 {
-		respondWithError(writer, http.StatusBadRequest, "Chirp is too long")
+	cleanedBody, bodyHeld, err := cfg.validateChirpBody(chirpReq.Body)
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid chirp: " + err.Error())
 		return
 	}
-	cleanedBody := getCleanedBody(chirpReq.Body)
 
-	if len(cleanedBody) == 0 {
-		respondWithError(writer, http.StatusBadRequest, "Chirp is empty")
+	visibility, err := validateVisibility(chirpReq.Visibility)
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid chirp: " + err.Error())
+		return
+	}
+
+	contentWarning, warningHeld, err := cfg.validateContentWarning(chirpReq.ContentWarning)
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid chirp: " + err.Error())
+		return
+	}
+
+	if len(chirpReq.MediaIDs) > maxMediaPerChirp {
+		respondWithError(writer, http.StatusBadRequest, fmt.Sprintf("Invalid chirp: at most %d images can be attached", maxMediaPerChirp))
+		return
+	}
+
+	var pollOptions []string
+	var pollHeld bool
+	if chirpReq.Poll != nil {
+		if chirpReq.PublishAt != nil {
+			respondWithError(writer, http.StatusBadRequest, "Invalid chirp: polls can't be scheduled")
+			return
+		}
+
+		pollOptions, pollHeld, err = cfg.validatePoll(*chirpReq.Poll)
+		if err != nil {
+			respondWithError(writer, http.StatusBadRequest, "Invalid chirp: " + err.Error())
+			return
+		}
+	}
+
+	if chirpReq.PublishAt != nil && !chirpReq.PublishAt.After(time.Now()) {
+		respondWithError(writer, http.StatusBadRequest, "Invalid chirp: publish_at must be in the future")
 		return
 	}
 
-	dbChirp, err := cfg.db.CreateChirp(req.Context(), database.CreateChirpParams{
+	var parent database.Chirp
+	if chirpReq.InReplyTo.Valid {
+		parent, err = cfg.db.GetChirpByID(req.Context(), database.GetChirpByIDParams{
+			ID: chirpReq.InReplyTo.UUID,
+			ViewerID: uuid.NullUUID{UUID: userID, Valid: true},
+		})
+		if err != nil || parent.TombstonedAt.Valid {
+			respondWithError(writer, http.StatusBadRequest, "Couldn't find the chirp being replied to")
+			return
+		}
+	}
+
+	if chirpReq.QuotedChirpID.Valid {
+		quoted, err := cfg.db.GetChirpByID(req.Context(), database.GetChirpByIDParams{
+			ID: chirpReq.QuotedChirpID.UUID,
+			ViewerID: uuid.NullUUID{UUID: userID, Valid: true},
+		})
+		if err != nil || quoted.TombstonedAt.Valid {
+			respondWithError(writer, http.StatusBadRequest, "Couldn't find the chirp being quoted")
+			return
+		}
+	}
+
+	// scheduled chirps are checked for spam when they're published, since
+	// that's when they join what's being posted
+	if chirpReq.PublishAt != nil {
+		cfg.scheduleChirp(writer, req, database.CreateScheduledChirpParams{
+			PublishAt: chirpReq.PublishAt.UTC(),
+			Body: cleanedBody,
+			UserID: userID,
+			ParentID: chirpReq.InReplyTo,
+			QuotedChirpID: chirpReq.QuotedChirpID,
+			Visibility: visibility,
+			ContentWarning: contentWarning,
+			Sensitive: chirpReq.Sensitive,
+		}, chirpReq.MediaIDs)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't start transaction: " + err.Error())
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	spamHeld, ok := cfg.screenSpam(writer, req, qtx, userID, cleanedBody, uuid.NullUUID{})
+	if !ok {
+		return
+	}
+
+	dbChirp, err := insertChirp(req.Context(), qtx, database.CreateChirpParams{
 		Body: cleanedBody,
 		UserID: userID,
+		ParentID: chirpReq.InReplyTo,
+		QuotedChirpID: chirpReq.QuotedChirpID,
+		Visibility: visibility,
+		ContentWarning: contentWarning,
+		Sensitive: chirpReq.Sensitive,
+		HeldForReview: bodyHeld || warningHeld || pollHeld || spamHeld,
 	})
 	if err != nil {
 		respondWithError(writer, http.StatusInternalServerError, "Couldn't create chirp: " + err.Error())
 		return
 	}
 
-	respondWithJSON(writer, http.StatusCreated, Chirp{
-		ID: dbChirp.ID,
-		CreatedAt: dbChirp.CreatedAt,
-		UpdatedAt: dbChirp.UpdatedAt,
-		Body: dbChirp.Body,
-		UserID: dbChirp.UserID,
-	})
+	if len(chirpReq.MediaIDs) > 0 {
+		attached, err := qtx.AttachMediaToChirp(req.Context(), database.AttachMediaToChirpParams{
+			ChirpID: uuid.NullUUID{UUID: dbChirp.ID, Valid: true},
+			MediaIds: chirpReq.MediaIDs,
+			UserID: userID,
+		})
+		if err != nil {
+			respondWithError(writer, http.StatusInternalServerError, "Couldn't attach media: " + err.Error())
+			return
+		}
+		if attached != int64(len(chirpReq.MediaIDs)) {
+			respondWithError(writer, http.StatusBadRequest, "Invalid chirp: media must be your own unattached uploads")
+			return
+		}
+	}
+
+	if chirpReq.Poll != nil {
+		if err := createPoll(req.Context(), qtx, dbChirp.ID, pollOptions, chirpReq.Poll.ClosesAt); err != nil {
+			respondWithError(writer, http.StatusInternalServerError, "Couldn't create poll: " + err.Error())
+			return
+		}
+	}
+
+	if chirpReq.InReplyTo.Valid {
+		if err := notifyReply(req.Context(), qtx, parent, dbChirp); err != nil {
+			respondWithError(writer, http.StatusInternalServerError, "Couldn't create notification: " + err.Error())
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't create chirp: " + err.Error())
+		return
+	}
+
+	chirp, err := cfg.buildChirp(req.Context(), dbChirp, uuid.NullUUID{UUID: userID, Valid: true})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't load chirp: " + err.Error())
+		return
+	}
+
+	respondWithJSON(writer, http.StatusCreated, chirp)
 }
 
 func (cfg *apiConfig) handlerGetChirps(writer http.ResponseWriter, req *http.Request) {
-	var dbChirps []database.Chirp
-	var err error
+	query := req.URL.Query()
 
-	if authorIdStr := req.URL.Query().Get("author_id"); authorIdStr != "" {
+	page, err := pagination.ParseParams(query)
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid pagination parameters: " + err.Error())
+		return
+	}
+
+	viewerID, err := cfg.getViewerID(req)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	var authorID uuid.NullUUID
+	if authorIdStr := query.Get("author_id"); authorIdStr != "" {
 		authorId, err := uuid.Parse(authorIdStr)
 		if err != nil {
 			respondWithError(writer, http.StatusBadRequest, "Invalid author ID: " + err.Error())
 			return
 		}
+		authorID = uuid.NullUUID{UUID: authorId, Valid: true}
+	}
+
+	// paging backwards walks the list in the opposite order, and
+	// pagination.Page flips the rows back round afterwards
+	descending := query.Get("sort") == "desc"
+	if page.Cursor != nil && page.Cursor.Direction == pagination.Prev {
+		descending = !descending
+	}
+
+	entries, err := cfg.listChirps(req.Context(), authorID, viewerID, page.Cursor, descending, page.Limit+1)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't retrieve chirps: " + err.Error())
+		return
+	}
 
-		dbChirps, err = cfg.db.GetChirpsByUserID(req.Context(), authorId)
+	entries, next, prev := pagination.Page(entries, page.Limit, page.Cursor, feedPosition)
+	if link := pagination.LinkHeader(req.URL, next, prev); link != "" {
+		writer.Header().Set("Link", link)
+	}
+
+	chirps, err := cfg.buildFeed(req.Context(), entries, viewerID)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't load chirps: " + err.Error())
+		return
+	}
+
+	respondWithJSON(writer, http.StatusOK, chirps)
+}
+
+// listChirps lists chirps for handlerGetChirps and profiles. A list for one
+// author includes the chirps they've rechirped.
+func (cfg *apiConfig) listChirps(ctx context.Context, authorID, viewerID uuid.NullUUID, cursor *pagination.Cursor, descending bool, limit int32) ([]feedEntry, error) {
+	entries := []feedEntry{}
+	switch {
+	case authorID.Valid && descending:
+		rows, err := cfg.db.GetChirpsByUserIDDesc(ctx, database.GetChirpsByUserIDDescParams{
+			UserID: authorID.UUID,
+			ViewerID: viewerID,
+			CursorCreatedAt: cursor.NullTime(),
+			CursorID: cursor.NullID(),
+			Limit: limit,
+		})
 		if err != nil {
-			respondWithError(writer, http.StatusInternalServerError, "Couldn't retrieve chirps: " + err.Error())
-			return
+			return nil, err
+		}
+		for _, row := range rows {
+			entries = append(entries, feedEntry(row))
 		}
-	} else {
-		dbChirps, err = cfg.db.GetChirps(req.Context())
+	case authorID.Valid:
+		rows, err := cfg.db.GetChirpsByUserID(ctx, database.GetChirpsByUserIDParams{
+			UserID: authorID.UUID,
+			ViewerID: viewerID,
+			CursorCreatedAt: cursor.NullTime(),
+			CursorID: cursor.NullID(),
+			Limit: limit,
+		})
 		if err != nil {
-			respondWithError(writer, http.StatusInternalServerError, "Couldn't retrieve chirps: " + err.Error())
-			return
+			return nil, err
 		}
-	}
-
-	// since queries are returned from the database in ASC order, we just look
-	// for "desc" to sort differently in that case
-	if s := req.URL.Query().Get("sort"); s != "" {
-		if s == "desc" {
-			sort.Slice(dbChirps, func(i, j int) bool {
-				return dbChirps[i].CreatedAt.After(dbChirps[j].CreatedAt)
-			})
+		for _, row := range rows {
+			entries = append(entries, feedEntry(row))
 		}
-	}
-
-	chirps := []Chirp{}
-	for _, dbChirp := range dbChirps {
-		chirps = append(chirps, Chirp{
-			ID: dbChirp.ID,
-			CreatedAt: dbChirp.CreatedAt,
-			UpdatedAt: dbChirp.UpdatedAt,
-			Body: dbChirp.Body,
-			UserID: dbChirp.UserID,
+	case descending:
+		dbChirps, err := cfg.db.GetChirpsDesc(ctx, database.GetChirpsDescParams{
+			ViewerID: viewerID,
+			CursorCreatedAt: cursor.NullTime(),
+			CursorID: cursor.NullID(),
+			Limit: limit,
 		})
+		if err != nil {
+			return nil, err
+		}
+		entries = chirpsToFeed(dbChirps)
+	default:
+		dbChirps, err := cfg.db.GetChirps(ctx, database.GetChirpsParams{
+			ViewerID: viewerID,
+			CursorCreatedAt: cursor.NullTime(),
+			CursorID: cursor.NullID(),
+			Limit: limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+		entries = chirpsToFeed(dbChirps)
 	}
+	return entries, nil
+}
 
-	respondWithJSON(writer, http.StatusOK, chirps)
+func chirpPosition(chirp database.Chirp) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: chirp.CreatedAt, ID: chirp.ID}
 }
 
 func (cfg *apiConfig) handlerGetChirpByID(writer http.ResponseWriter, req *http.Request) {
@@ -128,19 +359,40 @@ func (cfg *apiConfig) handlerGetChirpByID(writer http.ResponseWriter, req *http.
 		return
 	}
 
-	dbChirp, err := cfg.db.GetChirpByID(req.Context(), chirpID)
+	viewerID, err := cfg.getViewerID(req)
 	if err != nil {
-		respondWithError(writer, http.StatusNotFound, "Couldn't get chirp: " + err.Error())
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
 		return
 	}
 
-	respondWithJSON(writer, http.StatusOK, Chirp{
-		ID: dbChirp.ID,
-		CreatedAt: dbChirp.CreatedAt,
-		UpdatedAt: dbChirp.UpdatedAt,
-		Body: dbChirp.Body,
-		UserID: dbChirp.UserID,
+	dbChirp, err := cfg.db.GetChirpByID(req.Context(), database.GetChirpByIDParams{
+		ID: chirpID,
+		ViewerID: viewerID,
 	})
+	if err != nil {
+		respondWithError(writer, http.StatusNotFound, "Couldn't get chirp: " + err.Error())
+		return
+	}
+
+	if dbChirp.TombstonedAt.Valid {
+		respondWithError(writer, http.StatusNotFound, "Chirp has been deleted")
+		return
+	}
+
+	chirps, err := cfg.hydrateChirps(req.Context(), []database.Chirp{dbChirp}, viewerID)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't load chirp: " + err.Error())
+		return
+	}
+
+	if req.URL.Query().Get("reveal") != "true" {
+		if err := cfg.withholdSensitive(req.Context(), chirps, viewerID); err != nil {
+			respondWithError(writer, http.StatusInternalServerError, "Couldn't load chirp: " + err.Error())
+			return
+		}
+	}
+
+	respondWithJSON(writer, http.StatusOK, chirps[0])
 }
 
 func (cfg *apiConfig) handlerDeleteChirp(writer http.ResponseWriter, req *http.Request) {
@@ -162,19 +414,31 @@ func (cfg *apiConfig) handlerDeleteChirp(writer http.ResponseWriter, req *http.R
 		return
 	}
 
-	chirp, err := cfg.db.GetChirpByID(req.Context(), chirpID)
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
 	if err != nil {
-		respondWithError(writer, http.StatusNotFound, "Couldn't get chirp: " + err.Error())
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't start transaction: " + err.Error())
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	chirp, err := qtx.GetChirpByIDForUpdate(req.Context(), chirpID)
+	if err != nil || chirp.TombstonedAt.Valid {
+		respondWithError(writer, http.StatusNotFound, "Couldn't get chirp")
 		return
 	}
 
 	if chirp.UserID != userID {
-		respondWithError(writer, http.StatusForbidden, "Not authorized to delete chirp")
+		respondNotAuthor(writer, req, qtx, chirp, userID, "Not authorized to delete chirp")
 		return
 	}
 
-	err = cfg.db.DeleteChirp(req.Context(), chirpID)	
-	if err != nil {
+	if err := trashChirp(req.Context(), qtx, chirp, userID); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't delete chirp: " + err.Error())
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
 		respondWithError(writer, http.StatusInternalServerError, "Couldn't delete chirp: " + err.Error())
 		return
 	}
@@ -182,15 +446,304 @@ func (cfg *apiConfig) handlerDeleteChirp(writer http.ResponseWriter, req *http.R
 	writer.WriteHeader(http.StatusNoContent)
 }
 
-func getCleanedBody(text string) string {
-	profanityList := []string{"kerfuffle", "sharbert", "fornax"}
-	words := strings.Split(text, " ")
-	for i, word := range(words) {
-		for _, profanity := range profanityList {
-			if strings.ToLower(word) == profanity {
-				words[i] = "****"
+// insertChirp creates a chirp along with everything derived from its body.
+// q should belong to a transaction so the chirp never shows up half-made.
+func insertChirp(ctx context.Context, q *database.Queries, params database.CreateChirpParams) (database.Chirp, error) {
+	dbChirp, err := q.CreateChirp(ctx, params)
+	if err != nil {
+		return database.Chirp{}, err
+	}
+
+	if err := tagChirp(ctx, q, dbChirp); err != nil {
+		return database.Chirp{}, err
+	}
+
+	if err := saveFingerprint(ctx, q, dbChirp); err != nil {
+		return database.Chirp{}, err
+	}
+
+	return dbChirp, nil
+}
+
+// tagChirp links a chirp to the hashtags in its body. The links carry the
+// chirp's creation time so tag timelines can page without touching chirps.
+func tagChirp(ctx context.Context, q *database.Queries, dbChirp database.Chirp) error {
+	names := hashtags.Extract(dbChirp.Body)
+	if len(names) == 0 {
+		return nil
+	}
+
+	return q.TagChirp(ctx, database.TagChirpParams{
+		Names: names,
+		ChirpID: dbChirp.ID,
+		CreatedAt: dbChirp.CreatedAt,
+	})
+}
+
+// removeChirp deletes a chirp for good, leaving a tombstone in its place if
+// anything replies to it so the rest of the thread keeps its shape.
+// Tombstones left without any replies are cleaned up on the way back up the
+// thread. Chirps only get here by being purged from the trash.
+func removeChirp(ctx context.Context, q *database.Queries, chirp database.Chirp) error {
+	replies, err := q.CountChirpReplies(ctx, chirp.ID)
+	if err != nil {
+		return err
+	}
+
+	if replies > 0 {
+		if err := q.DeleteChirpRevisions(ctx, chirp.ID); err != nil {
+			return err
+		}
+		if err := q.DeleteChirpRechirps(ctx, chirp.ID); err != nil {
+			return err
+		}
+		if err := q.DeleteChirpLikes(ctx, chirp.ID); err != nil {
+			return err
+		}
+		if err := q.DeleteChirpTags(ctx, chirp.ID); err != nil {
+			return err
+		}
+		if err := q.DeleteChirpMedia(ctx, chirp.ID); err != nil {
+			return err
+		}
+		if err := q.DeletePoll(ctx, chirp.ID); err != nil {
+			return err
+		}
+		return q.TombstoneChirp(ctx, chirp.ID)
+	}
+
+	if err := q.DeleteChirp(ctx, chirp.ID); err != nil {
+		return err
+	}
+
+	parentID := chirp.ParentID
+	for parentID.Valid {
+		parent, err := q.GetChirpByIDForUpdate(ctx, parentID.UUID)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && !parent.TombstonedAt.Valid) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		replies, err := q.CountChirpReplies(ctx, parent.ID)
+		if err != nil {
+			return err
+		}
+		if replies > 0 {
+			return nil
+		}
+
+		if err := q.DeleteChirp(ctx, parent.ID); err != nil {
+			return err
+		}
+		parentID = parent.ParentID
+	}
+
+	return nil
+}
+
+func databaseChirpToChirp(dbChirp database.Chirp) Chirp {
+	chirp := Chirp{
+		ID: dbChirp.ID,
+		CreatedAt: dbChirp.CreatedAt,
+		UpdatedAt: dbChirp.UpdatedAt,
+		Body: dbChirp.Body,
+		UserID: dbChirp.UserID,
+		Visibility: dbChirp.Visibility,
+		ContentWarning: dbChirp.ContentWarning,
+		Sensitive: dbChirp.Sensitive,
+		HeldForReview: dbChirp.HeldForReview,
+		InReplyTo: dbChirp.ParentID,
+		QuotedChirpID: dbChirp.QuotedChirpID,
+		Deleted: dbChirp.TombstonedAt.Valid,
+	}
+	if dbChirp.DeletedAt.Valid {
+		chirp.DeletedAt = &dbChirp.DeletedAt.Time
+	}
+	return chirp
+}
+
+// buildChirps turns database rows into API chirps as viewerID should see
+// them, with sensitive chirps withheld according to their preference.
+func (cfg *apiConfig) buildChirps(ctx context.Context, dbChirps []database.Chirp, viewerID uuid.NullUUID) ([]Chirp, error) {
+	chirps, err := cfg.hydrateChirps(ctx, dbChirps, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cfg.withholdSensitive(ctx, chirps, viewerID); err != nil {
+		return nil, err
+	}
+
+	return chirps, nil
+}
+
+// hydrateChirps turns database rows into API chirps, loading anything that
+// lives in other tables with one query for the whole list rather than one
+// per chirp. Quoted chirps are embedded one level deep; a quote whose
+// original has since been deleted keeps its quoted_chirp_id but has no
+// quoted_chirp. liked_by_me is only filled in when there is a viewer.
+func (cfg *apiConfig) hydrateChirps(ctx context.Context, dbChirps []database.Chirp, viewerID uuid.NullUUID) ([]Chirp, error) {
+	chirpIDs := make([]uuid.UUID, 0, len(dbChirps))
+	quotedIDs := []uuid.UUID{}
+	for _, dbChirp := range dbChirps {
+		chirpIDs = append(chirpIDs, dbChirp.ID)
+		if dbChirp.QuotedChirpID.Valid {
+			quotedIDs = append(quotedIDs, dbChirp.QuotedChirpID.UUID)
+		}
+	}
+
+	replyCounts := map[uuid.UUID]int64{}
+	rechirpCounts := map[uuid.UUID]int64{}
+	likeCounts := map[uuid.UUID]int64{}
+	liked := map[uuid.UUID]bool{}
+	media := map[uuid.UUID][]MediaAttachment{}
+	polls := map[uuid.UUID]*Poll{}
+	if len(chirpIDs) > 0 {
+		replyRows, err := cfg.db.GetReplyCounts(ctx, database.GetReplyCountsParams{
+			ChirpIds: chirpIDs,
+			ViewerID: viewerID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range replyRows {
+			replyCounts[row.ChirpID] = row.ReplyCount
+		}
+
+		rechirpRows, err := cfg.db.GetRechirpCounts(ctx, database.GetRechirpCountsParams{
+			ChirpIds: chirpIDs,
+			ViewerID: viewerID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rechirpRows {
+			rechirpCounts[row.ChirpID] = row.RechirpCount
+		}
+
+		likeRows, err := cfg.db.GetLikeCounts(ctx, database.GetLikeCountsParams{
+			ChirpIds: chirpIDs,
+			ViewerID: viewerID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range likeRows {
+			likeCounts[row.ChirpID] = row.LikeCount
+		}
+
+		mediaRows, err := cfg.db.GetChirpMedia(ctx, chirpIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range mediaRows {
+			media[row.ChirpID.UUID] = append(media[row.ChirpID.UUID], cfg.databaseMediaToMedia(row))
+		}
+
+		polls, err = cfg.loadPolls(ctx, chirpIDs, viewerID)
+		if err != nil {
+			return nil, err
+		}
+
+		if viewerID.Valid {
+			likedIDs, err := cfg.db.GetLikedChirpIDs(ctx, database.GetLikedChirpIDsParams{
+				UserID: viewerID.UUID,
+				ChirpIds: chirpIDs,
+			})
+			if err != nil {
+				return nil, err
+			}
+			for _, likedID := range likedIDs {
+				liked[likedID] = true
 			}
 		}
 	}
-	return strings.Join(words, " ")
-}
\ No newline at end of file
+
+	quoted := map[uuid.UUID]database.Chirp{}
+	if len(quotedIDs) > 0 {
+		quotedChirps, err := cfg.db.GetChirpsByIDs(ctx, database.GetChirpsByIDsParams{
+			Ids: quotedIDs,
+			ViewerID: viewerID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, quotedChirp := range quotedChirps {
+			quoted[quotedChirp.ID] = quotedChirp
+		}
+	}
+
+	chirps := []Chirp{}
+	for _, dbChirp := range dbChirps {
+		chirp := databaseChirpToChirp(dbChirp)
+		chirp.ReplyCount = replyCounts[dbChirp.ID]
+		chirp.RechirpCount = rechirpCounts[dbChirp.ID]
+		chirp.LikeCount = likeCounts[dbChirp.ID]
+		chirp.Media = media[dbChirp.ID]
+		if chirp.Media == nil {
+			chirp.Media = []MediaAttachment{}
+		}
+		chirp.Poll = polls[dbChirp.ID]
+		if viewerID.Valid {
+			likedByMe := liked[dbChirp.ID]
+			chirp.LikedByMe = &likedByMe
+		}
+		if quotedChirp, ok := quoted[dbChirp.QuotedChirpID.UUID]; ok && dbChirp.QuotedChirpID.Valid {
+			embedded := databaseChirpToChirp(quotedChirp)
+			chirp.QuotedChirp = &embedded
+		}
+		if dbChirp.TombstonedAt.Valid {
+			// a chirp in the trash keeps its likes, media and poll until
+			// it's purged, but shown as a tombstone it has none of them
+			chirp.RechirpCount = 0
+			chirp.LikeCount = 0
+			chirp.Media = []MediaAttachment{}
+			chirp.Poll = nil
+			chirp.QuotedChirp = nil
+		}
+		chirps = append(chirps, chirp)
+	}
+
+	return chirps, nil
+}
+
+func (cfg *apiConfig) buildChirp(ctx context.Context, dbChirp database.Chirp, viewerID uuid.NullUUID) (Chirp, error) {
+	chirps, err := cfg.buildChirps(ctx, []database.Chirp{dbChirp}, viewerID)
+	if err != nil {
+		return Chirp{}, err
+	}
+
+	return chirps[0], nil
+}
+
+// validateChirpBody checks a chirp against the length limit and the
+// moderation rules, returning it with any masked terms replaced and whether
+// it should be held for review.
+func (cfg *apiConfig) validateChirpBody(body string) (string, bool, error) {
+	if len(body) > 400 {
+		return "", false, errors.New("chirp is too long")
+	}
+
+	cleanedBody, held, rejected := cfg.moderateText(body)
+	if rejected {
+		return "", false, errors.New("chirp contains a term that isn't allowed")
+	}
+	if len(cleanedBody) == 0 {
+		return "", false, errors.New("chirp is empty")
+	}
+
+	return cleanedBody, held, nil
+}
+
+func validateVisibility(visibility string) (string, error) {
+	switch visibility {
+	case "":
+		return VisibilityPublic, nil
+	case VisibilityPublic, VisibilityUnlisted, VisibilityPrivate:
+		return visibility, nil
+	}
+
+	return "", errors.New("visibility must be public, unlisted or private")
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/philipreese/chirpy-go/internal/database"
+)
+
+const maxContentWarningLength = 100
+
+// How a reader wants chirps with a content warning or the sensitive flag
+// shown. Expand shows them in full, hide leaves them out of every list, and
+// withhold returns them without their body and media until the reader asks
+// for the chirp with reveal=true. Anonymous readers get withhold.
+const (
+	SensitiveContentExpand   = "expand"
+	SensitiveContentHide     = "hide"
+	SensitiveContentWithhold = "withhold"
+)
+
+// validateContentWarning checks a content warning the same way as a chirp
+// body, reporting whether it should hold the chirp for review.
+func (cfg *apiConfig) validateContentWarning(contentWarning string) (string, bool, error) {
+	if utf8.RuneCountInString(contentWarning) > maxContentWarningLength {
+		return "", false, fmt.Errorf("content warning must be at most %d characters", maxContentWarningLength)
+	}
+
+	cleaned, held, rejected := cfg.moderateText(contentWarning)
+	if rejected {
+		return "", false, errors.New("content warning contains a term that isn't allowed")
+	}
+
+	return cleaned, held, nil
+}
+
+// handlerSetChirpSensitive lets moderators add or remove the sensitive flag
+// on anyone's chirp. The author's content warning is left alone.
+func (cfg *apiConfig) handlerSetChirpSensitive(writer http.ResponseWriter, req *http.Request) {
+	type sensitiveRequest struct {
+		Sensitive bool `json:"sensitive"`
+	}
+
+	chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid chirp ID: " + err.Error())
+		return
+	}
+
+	moderator, ok := cfg.requireRole(writer, req, RoleModerator, RoleAdmin)
+	if !ok {
+		return
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	var sensitiveReq sensitiveRequest
+	if err := decoder.Decode(&sensitiveReq); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't decode parameters: " + err.Error())
+		return
+	}
+
+	dbChirp, err := cfg.db.SetChirpSensitive(req.Context(), database.SetChirpSensitiveParams{
+		Sensitive: sensitiveReq.Sensitive,
+		ID: chirpID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(writer, http.StatusNotFound, "Chirp not found")
+			return
+		}
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't update chirp: " + err.Error())
+		return
+	}
+
+	// moderators need to see what they've flagged, whatever their own
+	// preference is
+	chirps, err := cfg.hydrateChirps(req.Context(), []database.Chirp{dbChirp}, uuid.NullUUID{UUID: moderator.ID, Valid: true})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't load chirp: " + err.Error())
+		return
+	}
+
+	respondWithJSON(writer, http.StatusOK, chirps[0])
+}
+
+// withholdSensitive blanks the body, media and poll of flagged chirps,
+// including embedded quotes, unless the viewer has chosen to have them
+// expanded.
+// Readers who hide them only meet them here by ID, through a quote or in a
+// thread, and get the same treatment. Authors always see their own chirps in full.
+func (cfg *apiConfig) withholdSensitive(ctx context.Context, chirps []Chirp, viewerID uuid.NullUUID) error {
+	if viewerID.Valid {
+		viewer, err := cfg.db.GetUserByID(ctx, viewerID.UUID)
+		if err != nil {
+			return err
+		}
+		if viewer.SensitiveContent == SensitiveContentExpand {
+			return nil
+		}
+	}
+
+	for i := range chirps {
+		withholdChirp(&chirps[i], viewerID)
+		if chirps[i].QuotedChirp != nil {
+			withholdChirp(chirps[i].QuotedChirp, viewerID)
+		}
+	}
+
+	return nil
+}
+
+func withholdChirp(chirp *Chirp, viewerID uuid.NullUUID) {
+	if !chirp.Sensitive && chirp.ContentWarning == "" {
+		return
+	}
+	if viewerID.Valid && chirp.UserID == viewerID.UUID {
+		return
+	}
+
+	chirp.Body = ""
+	chirp.Media = []MediaAttachment{}
+	chirp.Poll = nil
+	chirp.BodyWithheld = true
+}
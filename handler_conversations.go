@@ -0,0 +1,553 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/philipreese/chirpy-go/internal/auth"
+	"github.com/philipreese/chirpy-go/internal/database"
+	"github.com/philipreese/chirpy-go/internal/pagination"
+)
+
+const maxConversationParticipants = 10
+
+type Conversation struct {
+	ID           uuid.UUID                 `json:"id"`
+	CreatedAt    time.Time                 `json:"created_at"`
+	UpdatedAt    time.Time                 `json:"updated_at"`
+	Participants []ConversationParticipant `json:"participants"`
+	UnreadCount  int64                     `json:"unread_count"`
+}
+
+// ConversationParticipant doubles as the read receipt for a conversation:
+// everything sent up to last_read_at has been seen by that participant.
+type ConversationParticipant struct {
+	UserID     uuid.UUID  `json:"user_id"`
+	JoinedAt   time.Time  `json:"joined_at"`
+	LastReadAt *time.Time `json:"last_read_at"`
+}
+
+type Message struct {
+	ID             uuid.UUID `json:"id"`
+	ConversationID uuid.UUID `json:"conversation_id"`
+	SenderID       uuid.UUID `json:"sender_id"`
+	Body           string    `json:"body"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// handlerCreateConversation starts a conversation with one or more other
+// users. Asking for a one-to-one conversation that already exists returns
+// the existing one rather than starting a second.
+func (cfg *apiConfig) handlerCreateConversation(writer http.ResponseWriter, req *http.Request) {
+	type conversationRequest struct {
+		ParticipantIDs []uuid.UUID `json:"participant_ids"`
+	}
+
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't get bearer token: " + err.Error())
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	var conversationReq conversationRequest
+	if err := decoder.Decode(&conversationReq); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't decode parameters: " + err.Error())
+		return
+	}
+
+	otherIDs := []uuid.UUID{}
+	for _, participantID := range conversationReq.ParticipantIDs {
+		if participantID != userID && !slices.Contains(otherIDs, participantID) {
+			otherIDs = append(otherIDs, participantID)
+		}
+	}
+	if len(otherIDs) == 0 {
+		respondWithError(writer, http.StatusBadRequest, "Invalid conversation: needs at least one other participant")
+		return
+	}
+	if len(otherIDs)+1 > maxConversationParticipants {
+		respondWithError(writer, http.StatusBadRequest, "Invalid conversation: too many participants")
+		return
+	}
+
+	found, err := cfg.db.CountUsersByIDs(req.Context(), otherIDs)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't look up participants: " + err.Error())
+		return
+	}
+	if found != int64(len(otherIDs)) {
+		respondWithError(writer, http.StatusBadRequest, "Invalid conversation: unknown participant")
+		return
+	}
+
+	for _, otherID := range otherIDs {
+		blocked, err := cfg.db.BlockExists(req.Context(), database.BlockExistsParams{
+			UserID: userID,
+			OtherUserID: otherID,
+		})
+		if err != nil {
+			respondWithError(writer, http.StatusInternalServerError, "Couldn't check blocks: " + err.Error())
+			return
+		}
+		if blocked {
+			respondWithError(writer, http.StatusForbidden, "Can't message this user")
+			return
+		}
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't start transaction: " + err.Error())
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	status := http.StatusCreated
+	var dbConversation database.Conversation
+	if len(otherIDs) == 1 {
+		// two people starting a conversation with each other at once both
+		// wait here, so the second finds the one the first created
+		err = qtx.LockUserPair(req.Context(), database.LockUserPairParams{
+			UserID: userID,
+			OtherUserID: otherIDs[0],
+		})
+		if err != nil {
+			respondWithError(writer, http.StatusInternalServerError, "Couldn't look up conversation: " + err.Error())
+			return
+		}
+
+		dbConversation, err = qtx.FindDirectConversation(req.Context(), database.FindDirectConversationParams{
+			UserID: userID,
+			OtherUserID: otherIDs[0],
+		})
+		if err == nil {
+			status = http.StatusOK
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			respondWithError(writer, http.StatusInternalServerError, "Couldn't look up conversation: " + err.Error())
+			return
+		}
+	}
+
+	if status == http.StatusCreated {
+		dbConversation, err = qtx.CreateConversation(req.Context())
+		if err != nil {
+			respondWithError(writer, http.StatusInternalServerError, "Couldn't create conversation: " + err.Error())
+			return
+		}
+
+		for _, participantID := range append([]uuid.UUID{userID}, otherIDs...) {
+			err = qtx.AddConversationParticipant(req.Context(), database.AddConversationParticipantParams{
+				ConversationID: dbConversation.ID,
+				UserID: participantID,
+			})
+			if err != nil {
+				respondWithError(writer, http.StatusInternalServerError, "Couldn't add participant: " + err.Error())
+				return
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't create conversation: " + err.Error())
+		return
+	}
+
+	conversations, err := cfg.buildConversations(req.Context(), []database.Conversation{dbConversation}, []int64{0})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't load conversation: " + err.Error())
+		return
+	}
+
+	respondWithJSON(writer, status, conversations[0])
+}
+
+// handlerGetConversations lists the caller's conversations, most recently
+// active first. Conversations they deleted come back once someone sends a
+// new message.
+func (cfg *apiConfig) handlerGetConversations(writer http.ResponseWriter, req *http.Request) {
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't get bearer token: " + err.Error())
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	page, err := pagination.ParseForwardParams(req.URL.Query())
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid pagination parameters: " + err.Error())
+		return
+	}
+
+	rows, err := cfg.db.GetConversations(req.Context(), database.GetConversationsParams{
+		UserID: userID,
+		CursorCreatedAt: page.Cursor.NullTime(),
+		CursorID: page.Cursor.NullID(),
+		Limit: page.Limit + 1,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't retrieve conversations: " + err.Error())
+		return
+	}
+
+	rows, next, _ := pagination.Page(rows, page.Limit, page.Cursor, func(row database.GetConversationsRow) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: row.Conversation.UpdatedAt, ID: row.Conversation.ID}
+	})
+	if link := pagination.LinkHeader(req.URL, next, ""); link != "" {
+		writer.Header().Set("Link", link)
+	}
+
+	dbConversations := make([]database.Conversation, 0, len(rows))
+	unreadCounts := make([]int64, 0, len(rows))
+	for _, row := range rows {
+		dbConversations = append(dbConversations, row.Conversation)
+		unreadCounts = append(unreadCounts, row.UnreadCount)
+	}
+
+	conversations, err := cfg.buildConversations(req.Context(), dbConversations, unreadCounts)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't load conversations: " + err.Error())
+		return
+	}
+
+	respondWithJSON(writer, http.StatusOK, conversations)
+}
+
+func (cfg *apiConfig) handlerGetMessages(writer http.ResponseWriter, req *http.Request) {
+	conversationID, err := uuid.Parse(req.PathValue("conversationID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid conversation ID: " + err.Error())
+		return
+	}
+
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't get bearer token: " + err.Error())
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	page, err := pagination.ParseForwardParams(req.URL.Query())
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid pagination parameters: " + err.Error())
+		return
+	}
+
+	participant, err := cfg.db.GetConversationParticipant(req.Context(), database.GetConversationParticipantParams{
+		ConversationID: conversationID,
+		UserID: userID,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusNotFound, "Couldn't get conversation")
+		return
+	}
+
+	dbMessages, err := cfg.db.GetMessages(req.Context(), database.GetMessagesParams{
+		ConversationID: conversationID,
+		VisibleAfter: participant.DeletedAt,
+		CursorCreatedAt: page.Cursor.NullTime(),
+		CursorID: page.Cursor.NullID(),
+		Limit: page.Limit + 1,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't retrieve messages: " + err.Error())
+		return
+	}
+
+	dbMessages, next, _ := pagination.Page(dbMessages, page.Limit, page.Cursor, func(message database.Message) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: message.CreatedAt, ID: message.ID}
+	})
+	if link := pagination.LinkHeader(req.URL, next, ""); link != "" {
+		writer.Header().Set("Link", link)
+	}
+
+	messages := []Message{}
+	for _, dbMessage := range dbMessages {
+		messages = append(messages, databaseMessageToMessage(dbMessage))
+	}
+
+	respondWithJSON(writer, http.StatusOK, messages)
+}
+
+func (cfg *apiConfig) handlerSendMessage(writer http.ResponseWriter, req *http.Request) {
+	type messageRequest struct {
+		Body string `json:"body"`
+	}
+
+	conversationID, err := uuid.Parse(req.PathValue("conversationID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid conversation ID: " + err.Error())
+		return
+	}
+
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't get bearer token: " + err.Error())
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	var messageReq messageRequest
+	if err := decoder.Decode(&messageReq); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't decode parameters: " + err.Error())
+		return
+	}
+
+	// there's no review queue for private messages, so anything that would
+	// hold a chirp is turned away instead
+	cleanedBody, held, err := cfg.validateChirpBody(messageReq.Body)
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid message: " + err.Error())
+		return
+	}
+	if held {
+		respondWithError(writer, http.StatusBadRequest, "Invalid message: message contains a term that isn't allowed")
+		return
+	}
+
+	_, err = cfg.db.GetConversationParticipant(req.Context(), database.GetConversationParticipantParams{
+		ConversationID: conversationID,
+		UserID: userID,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusNotFound, "Couldn't get conversation")
+		return
+	}
+
+	blocked, err := cfg.db.ConversationHasBlock(req.Context(), database.ConversationHasBlockParams{
+		UserID: userID,
+		ConversationID: conversationID,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't check blocks: " + err.Error())
+		return
+	}
+	if blocked {
+		respondWithError(writer, http.StatusForbidden, "Can't message this conversation")
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't start transaction: " + err.Error())
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	dbMessage, err := qtx.CreateMessage(req.Context(), database.CreateMessageParams{
+		ConversationID: conversationID,
+		SenderID: userID,
+		Body: cleanedBody,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't send message: " + err.Error())
+		return
+	}
+
+	err = qtx.TouchConversation(req.Context(), database.TouchConversationParams{
+		ID: conversationID,
+		UpdatedAt: dbMessage.CreatedAt,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't update conversation: " + err.Error())
+		return
+	}
+
+	// the sender has obviously read everything up to their own message
+	err = qtx.MarkConversationRead(req.Context(), database.MarkConversationReadParams{
+		ConversationID: conversationID,
+		UserID: userID,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't update read receipt: " + err.Error())
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't send message: " + err.Error())
+		return
+	}
+
+	respondWithJSON(writer, http.StatusCreated, databaseMessageToMessage(dbMessage))
+}
+
+func (cfg *apiConfig) handlerMarkConversationRead(writer http.ResponseWriter, req *http.Request) {
+	conversationID, err := uuid.Parse(req.PathValue("conversationID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid conversation ID: " + err.Error())
+		return
+	}
+
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't get bearer token: " + err.Error())
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	_, err = cfg.db.GetConversationParticipant(req.Context(), database.GetConversationParticipantParams{
+		ConversationID: conversationID,
+		UserID: userID,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusNotFound, "Couldn't get conversation")
+		return
+	}
+
+	err = cfg.db.MarkConversationRead(req.Context(), database.MarkConversationReadParams{
+		ConversationID: conversationID,
+		UserID: userID,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't update read receipt: " + err.Error())
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+// handlerDeleteConversation removes a conversation for the caller only. The
+// other participants keep their copy; once everyone has deleted it, it is
+// removed for good.
+func (cfg *apiConfig) handlerDeleteConversation(writer http.ResponseWriter, req *http.Request) {
+	conversationID, err := uuid.Parse(req.PathValue("conversationID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid conversation ID: " + err.Error())
+		return
+	}
+
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't get bearer token: " + err.Error())
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't start transaction: " + err.Error())
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	_, err = qtx.GetConversationParticipant(req.Context(), database.GetConversationParticipantParams{
+		ConversationID: conversationID,
+		UserID: userID,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusNotFound, "Couldn't get conversation")
+		return
+	}
+
+	err = qtx.DeleteConversationForUser(req.Context(), database.DeleteConversationForUserParams{
+		ConversationID: conversationID,
+		UserID: userID,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't delete conversation: " + err.Error())
+		return
+	}
+
+	if err := qtx.DeleteAbandonedConversation(req.Context(), conversationID); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't delete conversation: " + err.Error())
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't delete conversation: " + err.Error())
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+// buildConversations attaches participants to a batch of conversations.
+// unreadCounts lines up with dbConversations.
+func (cfg *apiConfig) buildConversations(ctx context.Context, dbConversations []database.Conversation, unreadCounts []int64) ([]Conversation, error) {
+	conversationIDs := make([]uuid.UUID, 0, len(dbConversations))
+	for _, dbConversation := range dbConversations {
+		conversationIDs = append(conversationIDs, dbConversation.ID)
+	}
+
+	participants := map[uuid.UUID][]ConversationParticipant{}
+	if len(conversationIDs) > 0 {
+		dbParticipants, err := cfg.db.GetConversationParticipants(ctx, conversationIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, dbParticipant := range dbParticipants {
+			participant := ConversationParticipant{
+				UserID: dbParticipant.UserID,
+				JoinedAt: dbParticipant.JoinedAt,
+			}
+			if dbParticipant.LastReadAt.Valid {
+				participant.LastReadAt = &dbParticipant.LastReadAt.Time
+			}
+			participants[dbParticipant.ConversationID] = append(participants[dbParticipant.ConversationID], participant)
+		}
+	}
+
+	conversations := make([]Conversation, 0, len(dbConversations))
+	for i, dbConversation := range dbConversations {
+		conversations = append(conversations, Conversation{
+			ID: dbConversation.ID,
+			CreatedAt: dbConversation.CreatedAt,
+			UpdatedAt: dbConversation.UpdatedAt,
+			Participants: participants[dbConversation.ID],
+			UnreadCount: unreadCounts[i],
+		})
+	}
+
+	return conversations, nil
+}
+
+func databaseMessageToMessage(dbMessage database.Message) Message {
+	return Message{
+		ID: dbMessage.ID,
+		ConversationID: dbMessage.ConversationID,
+		SenderID: dbMessage.SenderID,
+		Body: dbMessage.Body,
+		CreatedAt: dbMessage.CreatedAt,
+	}
+}
@@ -0,0 +1,468 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/philipreese/chirpy-go/internal/auth"
+	"github.com/philipreese/chirpy-go/internal/database"
+	"github.com/philipreese/chirpy-go/internal/pagination"
+)
+
+// Drafts are unfinished, so they only get the chirp checks when published.
+// This cap just stops the table being used as free storage.
+const maxDraftLength = 4000
+
+type Draft struct {
+	ID             uuid.UUID     `json:"id"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+	Body           string        `json:"body"`
+	InReplyTo      uuid.NullUUID `json:"in_reply_to"`
+	QuotedChirpID  uuid.NullUUID `json:"quoted_chirp_id"`
+	Visibility     string        `json:"visibility"`
+	ContentWarning string        `json:"content_warning"`
+	Sensitive      bool          `json:"sensitive"`
+	Version        int32         `json:"version"`
+}
+
+type draftRequest struct {
+	Body           string        `json:"body"`
+	InReplyTo      uuid.NullUUID `json:"in_reply_to"`
+	QuotedChirpID  uuid.NullUUID `json:"quoted_chirp_id"`
+	Visibility     string        `json:"visibility"`
+	ContentWarning string        `json:"content_warning"`
+	Sensitive      bool          `json:"sensitive"`
+	Version        int32         `json:"version"`
+}
+
+func (cfg *apiConfig) handlerCreateDraft(writer http.ResponseWriter, req *http.Request) {
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't get bearer token: " + err.Error())
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	var draftReq draftRequest
+	if err := decoder.Decode(&draftReq); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't decode parameters: " + err.Error())
+		return
+	}
+
+	if utf8.RuneCountInString(draftReq.Body) > maxDraftLength {
+		respondWithError(writer, http.StatusBadRequest, fmt.Sprintf("Invalid draft: must be at most %d characters", maxDraftLength))
+		return
+	}
+
+	visibility, err := validateVisibility(draftReq.Visibility)
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid draft: " + err.Error())
+		return
+	}
+
+	if utf8.RuneCountInString(draftReq.ContentWarning) > maxContentWarningLength {
+		respondWithError(writer, http.StatusBadRequest, fmt.Sprintf("Invalid draft: content warning must be at most %d characters", maxContentWarningLength))
+		return
+	}
+
+	dbDraft, err := cfg.db.CreateDraft(req.Context(), database.CreateDraftParams{
+		ID: uuid.New(),
+		UserID: userID,
+		Body: draftReq.Body,
+		ParentID: draftReq.InReplyTo,
+		QuotedChirpID: draftReq.QuotedChirpID,
+		Visibility: visibility,
+		ContentWarning: draftReq.ContentWarning,
+		Sensitive: draftReq.Sensitive,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't create draft: " + err.Error())
+		return
+	}
+
+	respondWithJSON(writer, http.StatusCreated, databaseDraftToDraft(dbDraft))
+}
+
+func (cfg *apiConfig) handlerGetDrafts(writer http.ResponseWriter, req *http.Request) {
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't get bearer token: " + err.Error())
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	page, err := pagination.ParseForwardParams(req.URL.Query())
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid pagination parameters: " + err.Error())
+		return
+	}
+
+	dbDrafts, err := cfg.db.GetDrafts(req.Context(), database.GetDraftsParams{
+		UserID: userID,
+		CursorUpdatedAt: page.Cursor.NullTime(),
+		CursorID: page.Cursor.NullID(),
+		Limit: page.Limit + 1,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't retrieve drafts: " + err.Error())
+		return
+	}
+
+	dbDrafts, next, _ := pagination.Page(dbDrafts, page.Limit, page.Cursor, func(draft database.Draft) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: draft.UpdatedAt, ID: draft.ID}
+	})
+	if link := pagination.LinkHeader(req.URL, next, ""); link != "" {
+		writer.Header().Set("Link", link)
+	}
+
+	drafts := []Draft{}
+	for _, dbDraft := range dbDrafts {
+		drafts = append(drafts, databaseDraftToDraft(dbDraft))
+	}
+
+	respondWithJSON(writer, http.StatusOK, drafts)
+}
+
+func (cfg *apiConfig) handlerGetDraft(writer http.ResponseWriter, req *http.Request) {
+	draftID, err := uuid.Parse(req.PathValue("draftID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid draft ID: " + err.Error())
+		return
+	}
+
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't get bearer token: " + err.Error())
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	dbDraft, err := cfg.db.GetDraft(req.Context(), database.GetDraftParams{ID: draftID, UserID: userID})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(writer, http.StatusNotFound, "Draft not found")
+			return
+		}
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't retrieve draft: " + err.Error())
+		return
+	}
+
+	respondWithJSON(writer, http.StatusOK, databaseDraftToDraft(dbDraft))
+}
+
+// handlerSaveDraft replaces a draft with the request body. The request must
+// carry the version it was based on, and the save is refused with a 409 if
+// the draft has been saved from somewhere else since. Sending version 0
+// creates the draft under the ID in the path, so an editor can pick the ID
+// up front and autosave with PUT from the first keystroke.
+func (cfg *apiConfig) handlerSaveDraft(writer http.ResponseWriter, req *http.Request) {
+	draftID, err := uuid.Parse(req.PathValue("draftID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid draft ID: " + err.Error())
+		return
+	}
+
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't get bearer token: " + err.Error())
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	var draftReq draftRequest
+	if err := decoder.Decode(&draftReq); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't decode parameters: " + err.Error())
+		return
+	}
+
+	if utf8.RuneCountInString(draftReq.Body) > maxDraftLength {
+		respondWithError(writer, http.StatusBadRequest, fmt.Sprintf("Invalid draft: must be at most %d characters", maxDraftLength))
+		return
+	}
+
+	visibility, err := validateVisibility(draftReq.Visibility)
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid draft: " + err.Error())
+		return
+	}
+
+	if utf8.RuneCountInString(draftReq.ContentWarning) > maxContentWarningLength {
+		respondWithError(writer, http.StatusBadRequest, fmt.Sprintf("Invalid draft: content warning must be at most %d characters", maxContentWarningLength))
+		return
+	}
+
+	if draftReq.Version == 0 {
+		dbDraft, err := cfg.db.CreateDraft(req.Context(), database.CreateDraftParams{
+			ID: draftID,
+			UserID: userID,
+			Body: draftReq.Body,
+			ParentID: draftReq.InReplyTo,
+			QuotedChirpID: draftReq.QuotedChirpID,
+			Visibility: visibility,
+			ContentWarning: draftReq.ContentWarning,
+			Sensitive: draftReq.Sensitive,
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				respondWithError(writer, http.StatusConflict, "Draft already exists: save it with its current version")
+				return
+			}
+			respondWithError(writer, http.StatusInternalServerError, "Couldn't create draft: " + err.Error())
+			return
+		}
+
+		respondWithJSON(writer, http.StatusCreated, databaseDraftToDraft(dbDraft))
+		return
+	}
+
+	dbDraft, err := cfg.db.UpdateDraft(req.Context(), database.UpdateDraftParams{
+		Body: draftReq.Body,
+		ParentID: draftReq.InReplyTo,
+		QuotedChirpID: draftReq.QuotedChirpID,
+		Visibility: visibility,
+		ContentWarning: draftReq.ContentWarning,
+		Sensitive: draftReq.Sensitive,
+		ID: draftID,
+		UserID: userID,
+		Version: draftReq.Version,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		cfg.respondWithDraftConflict(writer, req, draftID, userID)
+		return
+	}
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't save draft: " + err.Error())
+		return
+	}
+
+	respondWithJSON(writer, http.StatusOK, databaseDraftToDraft(dbDraft))
+}
+
+func (cfg *apiConfig) handlerDeleteDraft(writer http.ResponseWriter, req *http.Request) {
+	draftID, err := uuid.Parse(req.PathValue("draftID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid draft ID: " + err.Error())
+		return
+	}
+
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't get bearer token: " + err.Error())
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	deleted, err := cfg.db.DeleteDraft(req.Context(), database.DeleteDraftParams{ID: draftID, UserID: userID})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't delete draft: " + err.Error())
+		return
+	}
+	if deleted == 0 {
+		respondWithError(writer, http.StatusNotFound, "Draft not found")
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+// handlerPublishDraft turns a draft into a chirp, with the same checks as
+// posting one directly. The draft is locked while it's published and
+// deleted in the same transaction, so it can only become one chirp.
+func (cfg *apiConfig) handlerPublishDraft(writer http.ResponseWriter, req *http.Request) {
+	type publishRequest struct {
+		Version int32 `json:"version"`
+	}
+
+	draftID, err := uuid.Parse(req.PathValue("draftID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid draft ID: " + err.Error())
+		return
+	}
+
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't get bearer token: " + err.Error())
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	var publishReq publishRequest
+	if err := decoder.Decode(&publishReq); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't decode parameters: " + err.Error())
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't start transaction: " + err.Error())
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	dbDraft, err := qtx.GetDraftForUpdate(req.Context(), database.GetDraftForUpdateParams{ID: draftID, UserID: userID})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(writer, http.StatusNotFound, "Draft not found")
+			return
+		}
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't retrieve draft: " + err.Error())
+		return
+	}
+
+	if dbDraft.Version != publishReq.Version {
+		respondWithError(writer, http.StatusConflict, fmt.Sprintf("Draft has been saved elsewhere: the current version is %d", dbDraft.Version))
+		return
+	}
+
+	cleanedBody, bodyHeld, err := cfg.validateChirpBody(dbDraft.Body)
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid chirp: " + err.Error())
+		return
+	}
+
+	contentWarning, warningHeld, err := cfg.validateContentWarning(dbDraft.ContentWarning)
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid chirp: " + err.Error())
+		return
+	}
+
+	var parent database.Chirp
+	if dbDraft.ParentID.Valid {
+		parent, err = qtx.GetChirpByID(req.Context(), database.GetChirpByIDParams{
+			ID: dbDraft.ParentID.UUID,
+			ViewerID: uuid.NullUUID{UUID: userID, Valid: true},
+		})
+		if err != nil || parent.TombstonedAt.Valid {
+			respondWithError(writer, http.StatusBadRequest, "Couldn't find the chirp being replied to")
+			return
+		}
+	}
+
+	if dbDraft.QuotedChirpID.Valid {
+		quoted, err := qtx.GetChirpByID(req.Context(), database.GetChirpByIDParams{
+			ID: dbDraft.QuotedChirpID.UUID,
+			ViewerID: uuid.NullUUID{UUID: userID, Valid: true},
+		})
+		if err != nil || quoted.TombstonedAt.Valid {
+			respondWithError(writer, http.StatusBadRequest, "Couldn't find the chirp being quoted")
+			return
+		}
+	}
+
+	spamHeld, ok := cfg.screenSpam(writer, req, qtx, userID, cleanedBody, uuid.NullUUID{})
+	if !ok {
+		return
+	}
+
+	dbChirp, err := insertChirp(req.Context(), qtx, database.CreateChirpParams{
+		Body: cleanedBody,
+		UserID: userID,
+		ParentID: dbDraft.ParentID,
+		QuotedChirpID: dbDraft.QuotedChirpID,
+		Visibility: dbDraft.Visibility,
+		ContentWarning: contentWarning,
+		Sensitive: dbDraft.Sensitive,
+		HeldForReview: bodyHeld || warningHeld || spamHeld,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't create chirp: " + err.Error())
+		return
+	}
+
+	if dbDraft.ParentID.Valid {
+		if err := notifyReply(req.Context(), qtx, parent, dbChirp); err != nil {
+			respondWithError(writer, http.StatusInternalServerError, "Couldn't create notification: " + err.Error())
+			return
+		}
+	}
+
+	if _, err := qtx.DeleteDraft(req.Context(), database.DeleteDraftParams{ID: draftID, UserID: userID}); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't delete draft: " + err.Error())
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't publish draft: " + err.Error())
+		return
+	}
+
+	chirp, err := cfg.buildChirp(req.Context(), dbChirp, uuid.NullUUID{UUID: userID, Valid: true})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't load chirp: " + err.Error())
+		return
+	}
+
+	respondWithJSON(writer, http.StatusCreated, chirp)
+}
+
+// respondWithDraftConflict explains why a versioned save matched nothing:
+// either the draft is gone or it has moved on to a newer version.
+func (cfg *apiConfig) respondWithDraftConflict(writer http.ResponseWriter, req *http.Request, draftID, userID uuid.UUID) {
+	dbDraft, err := cfg.db.GetDraft(req.Context(), database.GetDraftParams{ID: draftID, UserID: userID})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(writer, http.StatusNotFound, "Draft not found")
+			return
+		}
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't retrieve draft: " + err.Error())
+		return
+	}
+
+	respondWithError(writer, http.StatusConflict, fmt.Sprintf("Draft has been saved elsewhere: the current version is %d", dbDraft.Version))
+}
+
+func databaseDraftToDraft(dbDraft database.Draft) Draft {
+	return Draft{
+		ID: dbDraft.ID,
+		CreatedAt: dbDraft.CreatedAt,
+		UpdatedAt: dbDraft.UpdatedAt,
+		Body: dbDraft.Body,
+		InReplyTo: dbDraft.ParentID,
+		QuotedChirpID: dbDraft.QuotedChirpID,
+		Visibility: dbDraft.Visibility,
+		ContentWarning: dbDraft.ContentWarning,
+		Sensitive: dbDraft.Sensitive,
+		Version: dbDraft.Version,
+	}
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/philipreese/chirpy-go/internal/auth"
+	"github.com/philipreese/chirpy-go/internal/database"
+	"github.com/philipreese/chirpy-go/internal/pagination"
+)
+
+type FollowedUser struct {
+	Profile
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+func (cfg *apiConfig) handlerFollowUser(writer http.ResponseWriter, req *http.Request) {
+	followeeID, err := uuid.Parse(req.PathValue("userID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid user ID: " + err.Error())
+		return
+	}
+
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't get bearer token: " + err.Error())
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	if followeeID == userID {
+		respondWithError(writer, http.StatusBadRequest, "Can't follow yourself")
+		return
+	}
+
+	if _, err := cfg.db.GetUserByID(req.Context(), followeeID); err != nil {
+		respondWithError(writer, http.StatusNotFound, "Couldn't get user")
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't start transaction: " + err.Error())
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	// locked the same way as blocking, so a block can't land between the
+	// check and the follow
+	err = qtx.LockUserPair(req.Context(), database.LockUserPairParams{
+		UserID: userID,
+		OtherUserID: followeeID,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't follow user: " + err.Error())
+		return
+	}
+
+	blocked, err := qtx.BlockExists(req.Context(), database.BlockExistsParams{
+		UserID: userID,
+		OtherUserID: followeeID,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't check blocks: " + err.Error())
+		return
+	}
+	if blocked {
+		respondWithError(writer, http.StatusForbidden, "Can't follow this user")
+		return
+	}
+
+	err = qtx.FollowUser(req.Context(), database.FollowUserParams{
+		FollowerID: userID,
+		FolloweeID: followeeID,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't follow user: " + err.Error())
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't follow user: " + err.Error())
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerUnfollowUser(writer http.ResponseWriter, req *http.Request) {
+	followeeID, err := uuid.Parse(req.PathValue("userID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid user ID: " + err.Error())
+		return
+	}
+
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't get bearer token: " + err.Error())
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	err = cfg.db.UnfollowUser(req.Context(), database.UnfollowUserParams{
+		FollowerID: userID,
+		FolloweeID: followeeID,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't unfollow user: " + err.Error())
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerGetFollowers(writer http.ResponseWriter, req *http.Request) {
+	userID, err := uuid.Parse(req.PathValue("userID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid user ID: " + err.Error())
+		return
+	}
+
+	page, err := pagination.ParseForwardParams(req.URL.Query())
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid pagination parameters: " + err.Error())
+		return
+	}
+
+	if _, err := cfg.db.GetUserByID(req.Context(), userID); err != nil {
+		respondWithError(writer, http.StatusNotFound, "Couldn't get user")
+		return
+	}
+
+	rows, err := cfg.db.GetFollowers(req.Context(), database.GetFollowersParams{
+		UserID: userID,
+		CursorCreatedAt: page.Cursor.NullTime(),
+		CursorID: page.Cursor.NullID(),
+		Limit: page.Limit + 1,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't retrieve followers: " + err.Error())
+		return
+	}
+
+	rows, next, _ := pagination.Page(rows, page.Limit, page.Cursor, func(row database.GetFollowersRow) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: row.FollowedAt, ID: row.User.ID}
+	})
+	if link := pagination.LinkHeader(req.URL, next, ""); link != "" {
+		writer.Header().Set("Link", link)
+	}
+
+	followers := []FollowedUser{}
+	for _, row := range rows {
+		followers = append(followers, FollowedUser{
+			Profile: databaseUserToProfile(row.User),
+			FollowedAt: row.FollowedAt,
+		})
+	}
+
+	respondWithJSON(writer, http.StatusOK, followers)
+}
+
+func (cfg *apiConfig) handlerGetFollowing(writer http.ResponseWriter, req *http.Request) {
+	userID, err := uuid.Parse(req.PathValue("userID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid user ID: " + err.Error())
+		return
+	}
+
+	page, err := pagination.ParseForwardParams(req.URL.Query())
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid pagination parameters: " + err.Error())
+		return
+	}
+
+	if _, err := cfg.db.GetUserByID(req.Context(), userID); err != nil {
+		respondWithError(writer, http.StatusNotFound, "Couldn't get user")
+		return
+	}
+
+	rows, err := cfg.db.GetFollowing(req.Context(), database.GetFollowingParams{
+		UserID: userID,
+		CursorCreatedAt: page.Cursor.NullTime(),
+		CursorID: page.Cursor.NullID(),
+		Limit: page.Limit + 1,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't retrieve followed users: " + err.Error())
+		return
+	}
+
+	rows, next, _ := pagination.Page(rows, page.Limit, page.Cursor, func(row database.GetFollowingRow) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: row.FollowedAt, ID: row.User.ID}
+	})
+	if link := pagination.LinkHeader(req.URL, next, ""); link != "" {
+		writer.Header().Set("Link", link)
+	}
+
+	following := []FollowedUser{}
+	for _, row := range rows {
+		following = append(following, FollowedUser{
+			Profile: databaseUserToProfile(row.User),
+			FollowedAt: row.FollowedAt,
+		})
+	}
+
+	respondWithJSON(writer, http.StatusOK, following)
+}
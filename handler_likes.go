@@ -0,0 +1,202 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/philipreese/chirpy-go/internal/auth"
+	"github.com/philipreese/chirpy-go/internal/database"
+	"github.com/philipreese/chirpy-go/internal/pagination"
+)
+
+type ChirpLike struct {
+	UserID    uuid.UUID `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (cfg *apiConfig) handlerLikeChirp(writer http.ResponseWriter, req *http.Request) {
+	chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid chirp ID: " + err.Error())
+		return
+	}
+
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't get bearer token: " + err.Error())
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	chirp, err := cfg.db.GetChirpByID(req.Context(), database.GetChirpByIDParams{
+		ID: chirpID,
+		ViewerID: uuid.NullUUID{UUID: userID, Valid: true},
+	})
+	if err != nil || chirp.TombstonedAt.Valid {
+		respondWithError(writer, http.StatusNotFound, "Couldn't get chirp")
+		return
+	}
+
+	err = cfg.db.LikeChirp(req.Context(), database.LikeChirpParams{
+		UserID: userID,
+		ChirpID: chirp.ID,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't like chirp: " + err.Error())
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerUnlikeChirp(writer http.ResponseWriter, req *http.Request) {
+	chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid chirp ID: " + err.Error())
+		return
+	}
+
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't get bearer token: " + err.Error())
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	err = cfg.db.UnlikeChirp(req.Context(), database.UnlikeChirpParams{
+		UserID: userID,
+		ChirpID: chirpID,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't unlike chirp: " + err.Error())
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerGetChirpLikes(writer http.ResponseWriter, req *http.Request) {
+	chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid chirp ID: " + err.Error())
+		return
+	}
+
+	page, err := pagination.ParseForwardParams(req.URL.Query())
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid pagination parameters: " + err.Error())
+		return
+	}
+
+	viewerID, err := cfg.getViewerID(req)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	chirp, err := cfg.db.GetChirpByID(req.Context(), database.GetChirpByIDParams{
+		ID: chirpID,
+		ViewerID: viewerID,
+	})
+	if err != nil || chirp.TombstonedAt.Valid {
+		respondWithError(writer, http.StatusNotFound, "Couldn't get chirp")
+		return
+	}
+
+	dbLikes, err := cfg.db.GetChirpLikes(req.Context(), database.GetChirpLikesParams{
+		ChirpID: chirp.ID,
+		ViewerID: viewerID,
+		CursorCreatedAt: page.Cursor.NullTime(),
+		CursorID: page.Cursor.NullID(),
+		Limit: page.Limit + 1,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't retrieve likes: " + err.Error())
+		return
+	}
+
+	dbLikes, next, _ := pagination.Page(dbLikes, page.Limit, page.Cursor, func(like database.ChirpLike) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: like.CreatedAt, ID: like.UserID}
+	})
+	if link := pagination.LinkHeader(req.URL, next, ""); link != "" {
+		writer.Header().Set("Link", link)
+	}
+
+	likes := []ChirpLike{}
+	for _, dbLike := range dbLikes {
+		likes = append(likes, ChirpLike{
+			UserID: dbLike.UserID,
+			CreatedAt: dbLike.CreatedAt,
+		})
+	}
+
+	respondWithJSON(writer, http.StatusOK, likes)
+}
+
+func (cfg *apiConfig) handlerGetUserLikes(writer http.ResponseWriter, req *http.Request) {
+	userID, err := uuid.Parse(req.PathValue("userID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid user ID: " + err.Error())
+		return
+	}
+
+	viewerID, err := cfg.getViewerID(req)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	page, err := pagination.ParseForwardParams(req.URL.Query())
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid pagination parameters: " + err.Error())
+		return
+	}
+
+	if _, err := cfg.db.GetUserByID(req.Context(), userID); err != nil {
+		respondWithError(writer, http.StatusNotFound, "Couldn't get user")
+		return
+	}
+
+	rows, err := cfg.db.GetLikedChirps(req.Context(), database.GetLikedChirpsParams{
+		UserID: userID,
+		ViewerID: viewerID,
+		CursorCreatedAt: page.Cursor.NullTime(),
+		CursorID: page.Cursor.NullID(),
+		Limit: page.Limit + 1,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't retrieve liked chirps: " + err.Error())
+		return
+	}
+
+	rows, next, _ := pagination.Page(rows, page.Limit, page.Cursor, func(row database.GetLikedChirpsRow) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: row.LikedAt, ID: row.Chirp.ID}
+	})
+	if link := pagination.LinkHeader(req.URL, next, ""); link != "" {
+		writer.Header().Set("Link", link)
+	}
+
+	dbChirps := make([]database.Chirp, 0, len(rows))
+	for _, row := range rows {
+		dbChirps = append(dbChirps, row.Chirp)
+	}
+
+	chirps, err := cfg.buildChirps(req.Context(), dbChirps, viewerID)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't load chirps: " + err.Error())
+		return
+	}
+
+	respondWithJSON(writer, http.StatusOK, chirps)
+}
@@ -1,11 +1,16 @@
 package main
 
 import (
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"encoding/json"
+	"log"
+	"net"
 	"net/http"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/philipreese/chirpy-go/internal/auth"
 	"github.com/philipreese/chirpy-go/internal/database"
 )
@@ -35,6 +40,17 @@ func (cfg *apiConfig) handlerLogin(writer http.ResponseWriter, req *http.Request
 		return
 	}
 
+	if isSuspended(user) {
+		respondWithError(writer, http.StatusForbidden, suspensionMessage(user))
+		return
+	}
+
+	// the new device notice is a courtesy, so failing to send it is no
+	// reason to keep someone with the right password out
+	if err := cfg.recordLoginDevice(req, user.ID); err != nil {
+		log.Printf("Couldn't record login device: %v", err)
+	}
+
 	tokenString, err := auth.MakeJWT(user.ID, cfg.tokenSecret, time.Hour)
 	if err != nil {		
 		respondWithError(writer, http.StatusInternalServerError, "Failed to create token: " + err.Error())
@@ -59,14 +75,62 @@ func (cfg *apiConfig) handlerLogin(writer http.ResponseWriter, req *http.Request
 	}
 
 	respondWithJSON(writer, http.StatusOK, loginResponse{
-		User: User{
-			ID: user.ID,
-			CreatedAt: user.CreatedAt,
-			UpdatedAt: user.UpdatedAt,
-			Email: user.Email,
-			IsChirpyRed: user.IsChirpyRed,
-		},
+		User: databaseUserToUser(user),
 		Token: tokenString,
 		RefreshToken: refreshToken,
 	})
-}
\ No newline at end of file
+}
+
+// recordLoginDevice remembers the device a user logged in from, telling them
+// when it's one we haven't seen before. Devices are told apart by their
+// User-Agent, which is only a hint, but enough to flag a login worth a look.
+// The first device an account ever uses doesn't count as new.
+func (cfg *apiConfig) recordLoginDevice(req *http.Request, userID uuid.UUID) error {
+	userAgent := req.UserAgent()
+	hash := sha256.Sum256([]byte(userAgent))
+	deviceHash := hex.EncodeToString(hash[:])
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	knownDevices, err := qtx.CountLoginDevices(req.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	created, err := qtx.CreateLoginDevice(req.Context(), database.CreateLoginDeviceParams{
+		UserID: userID,
+		DeviceHash: deviceHash,
+	})
+	if err != nil {
+		return err
+	}
+
+	if created == 0 {
+		err = qtx.TouchLoginDevice(req.Context(), database.TouchLoginDeviceParams{
+			UserID: userID,
+			DeviceHash: deviceHash,
+		})
+		if err != nil {
+			return err
+		}
+	} else if knownDevices > 0 {
+		ipAddress, _, err := net.SplitHostPort(req.RemoteAddr)
+		if err != nil {
+			ipAddress = req.RemoteAddr
+		}
+		err = notify(req.Context(), qtx, userID, NotificationNewLogin, newLoginPayload{
+			UserAgent: userAgent,
+			IPAddress: ipAddress,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
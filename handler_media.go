@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/philipreese/chirpy-go/internal/auth"
+	"github.com/philipreese/chirpy-go/internal/database"
+	"github.com/philipreese/chirpy-go/internal/imaging"
+)
+
+const (
+	maxUploadSize    = 5 << 20
+	maxAltTextLength = 1000
+	maxMediaPerChirp = 4
+)
+
+type MediaAttachment struct {
+	ID              uuid.UUID `json:"id"`
+	URL             string    `json:"url"`
+	ThumbnailURL    string    `json:"thumbnail_url"`
+	ContentType     string    `json:"content_type"`
+	Width           int32     `json:"width"`
+	Height          int32     `json:"height"`
+	ThumbnailWidth  int32     `json:"thumbnail_width"`
+	ThumbnailHeight int32     `json:"thumbnail_height"`
+	AltText         string    `json:"alt_text"`
+}
+
+// handlerUploadMedia takes a multipart upload with the image in a "file"
+// field and optional "alt_text". The stored copy is re-encoded, so it
+// carries none of the original's metadata. Uploads stay unattached until
+// their ID is passed in media_ids when posting a chirp.
+func (cfg *apiConfig) handlerUploadMedia(writer http.ResponseWriter, req *http.Request) {
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't get bearer token: " + err.Error())
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	// leave room for the rest of the form around the file itself
+	req.Body = http.MaxBytesReader(writer, req.Body, maxUploadSize+(1<<20))
+	if err := req.ParseMultipartForm(maxUploadSize); err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Couldn't parse upload: " + err.Error())
+		return
+	}
+
+	file, _, err := req.FormFile("file")
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Couldn't get file: " + err.Error())
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxUploadSize+1))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Couldn't read file: " + err.Error())
+		return
+	}
+	if len(data) > maxUploadSize {
+		respondWithError(writer, http.StatusRequestEntityTooLarge, fmt.Sprintf("Image is too large: the limit is %d MB", maxUploadSize>>20))
+		return
+	}
+
+	altText := req.FormValue("alt_text")
+	if utf8.RuneCountInString(altText) > maxAltTextLength {
+		respondWithError(writer, http.StatusBadRequest, fmt.Sprintf("Invalid alt text: must be at most %d characters", maxAltTextLength))
+		return
+	}
+
+	processed, err := imaging.Process(data)
+	if err != nil {
+		if errors.Is(err, imaging.ErrUnsupportedType) {
+			respondWithError(writer, http.StatusUnsupportedMediaType, "Invalid image: must be a JPEG, PNG or GIF")
+			return
+		}
+		respondWithError(writer, http.StatusBadRequest, "Invalid image: " + err.Error())
+		return
+	}
+
+	mediaID := uuid.New()
+	extension := map[string]string{"image/jpeg": ".jpg", "image/png": ".png", "image/gif": ".gif"}
+	storageKey := mediaID.String() + extension[processed.Original.ContentType]
+	thumbnailKey := mediaID.String() + "_thumb" + extension[processed.Thumbnail.ContentType]
+
+	if err := cfg.blobs.Put(req.Context(), storageKey, bytes.NewReader(processed.Original.Data)); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't store image: " + err.Error())
+		return
+	}
+	if err := cfg.blobs.Put(req.Context(), thumbnailKey, bytes.NewReader(processed.Thumbnail.Data)); err != nil {
+		cfg.blobs.Delete(req.Context(), storageKey)
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't store thumbnail: " + err.Error())
+		return
+	}
+
+	dbMedia, err := cfg.db.CreateMediaAttachment(req.Context(), database.CreateMediaAttachmentParams{
+		ID: mediaID,
+		UserID: userID,
+		ContentType: processed.Original.ContentType,
+		StorageKey: storageKey,
+		ThumbnailKey: thumbnailKey,
+		Width: int32(processed.Original.Width),
+		Height: int32(processed.Original.Height),
+		ThumbnailWidth: int32(processed.Thumbnail.Width),
+		ThumbnailHeight: int32(processed.Thumbnail.Height),
+		SizeBytes: int32(len(processed.Original.Data)),
+		AltText: altText,
+	})
+	if err != nil {
+		cfg.blobs.Delete(req.Context(), storageKey)
+		cfg.blobs.Delete(req.Context(), thumbnailKey)
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't save media: " + err.Error())
+		return
+	}
+
+	respondWithJSON(writer, http.StatusCreated, cfg.databaseMediaToMedia(dbMedia))
+}
+
+func (cfg *apiConfig) databaseMediaToMedia(dbMedia database.MediaAttachment) MediaAttachment {
+	return MediaAttachment{
+		ID: dbMedia.ID,
+		URL: cfg.blobs.URL(dbMedia.StorageKey),
+		ThumbnailURL: cfg.blobs.URL(dbMedia.ThumbnailKey),
+		ContentType: dbMedia.ContentType,
+		Width: dbMedia.Width,
+		Height: dbMedia.Height,
+		ThumbnailWidth: dbMedia.ThumbnailWidth,
+		ThumbnailHeight: dbMedia.ThumbnailHeight,
+		AltText: dbMedia.AltText,
+	}
+}
+
+// middlewareMediaAccess only lets a stored image through to whoever can see
+// it: its uploader, even once its chirp is in the trash, and otherwise
+// viewers who can open its chirp and aren't having its body withheld, unless they
+// pass reveal=true as they would for the chirp. Anything else is a 404, so
+// knowing a key gives nothing away.
+func (cfg *apiConfig) middlewareMediaAccess(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		viewerID, err := cfg.getViewerID(req)
+		if err != nil {
+			respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+			return
+		}
+
+		dbMedia, err := cfg.db.GetMediaAttachmentByKey(req.Context(), strings.TrimPrefix(req.URL.Path, "/"))
+		if err != nil {
+			http.NotFound(writer, req)
+			return
+		}
+
+		if viewerID.Valid && viewerID.UUID == dbMedia.UserID {
+			next.ServeHTTP(writer, req)
+			return
+		}
+		if !dbMedia.ChirpID.Valid {
+			http.NotFound(writer, req)
+			return
+		}
+
+		dbChirp, err := cfg.db.GetChirpByID(req.Context(), database.GetChirpByIDParams{
+			ID: dbMedia.ChirpID.UUID,
+			ViewerID: viewerID,
+		})
+		if err != nil || dbChirp.TombstonedAt.Valid {
+			http.NotFound(writer, req)
+			return
+		}
+
+		if req.URL.Query().Get("reveal") != "true" {
+			chirps := []Chirp{databaseChirpToChirp(dbChirp)}
+			if err := cfg.withholdSensitive(req.Context(), chirps, viewerID); err != nil {
+				respondWithError(writer, http.StatusInternalServerError, "Couldn't load chirp: " + err.Error())
+				return
+			}
+			if chirps[0].BodyWithheld {
+				http.NotFound(writer, req)
+				return
+			}
+		}
+
+		next.ServeHTTP(writer, req)
+	})
+}
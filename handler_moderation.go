@@ -0,0 +1,389 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/philipreese/chirpy-go/internal/database"
+	"github.com/philipreese/chirpy-go/internal/moderation"
+	"github.com/philipreese/chirpy-go/internal/pagination"
+)
+
+// Rules are reloaded from the database this often, so changes made through
+// another instance reach this one without a restart.
+const moderationRulesReloadInterval = 15 * time.Second
+
+type ModerationRule struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Kind      string    `json:"kind"`
+	Pattern   string    `json:"pattern"`
+	Action    string    `json:"action"`
+}
+
+type moderationRuleRequest struct {
+	Kind    string `json:"kind"`
+	Pattern string `json:"pattern"`
+	Action  string `json:"action"`
+}
+
+// moderateText runs text through the moderation rules, returning it with
+// masked terms replaced and whether a rule asked for it to be held for
+// review or rejected outright.
+func (cfg *apiConfig) moderateText(text string) (cleaned string, held bool, rejected bool) {
+	result := cfg.moderation.Check(text)
+	return result.Text, result.Held, result.Rejected
+}
+
+// loadModerationRules replaces the engine's rules with the ones in the
+// database. If any of them doesn't compile, the old rules stay in use.
+func (cfg *apiConfig) loadModerationRules(ctx context.Context) error {
+	dbRules, err := cfg.db.GetModerationRules(ctx)
+	if err != nil {
+		return err
+	}
+
+	rules := make([]moderation.Rule, 0, len(dbRules))
+	for _, dbRule := range dbRules {
+		rules = append(rules, moderation.Rule{
+			ID: dbRule.ID,
+			Kind: dbRule.Kind,
+			Pattern: dbRule.Pattern,
+			Action: dbRule.Action,
+		})
+	}
+
+	return cfg.moderation.Load(rules)
+}
+
+// reloadModerationRules reloads the rules every interval until ctx is
+// cancelled.
+func (cfg *apiConfig) reloadModerationRules(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := cfg.loadModerationRules(ctx); err != nil {
+			log.Printf("Couldn't reload moderation rules: %v", err)
+		}
+	}
+}
+
+func (cfg *apiConfig) handlerGetModerationRules(writer http.ResponseWriter, req *http.Request) {
+	if _, ok := cfg.requireRole(writer, req, RoleAdmin); !ok {
+		return
+	}
+
+	dbRules, err := cfg.db.GetModerationRules(req.Context())
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't retrieve moderation rules: " + err.Error())
+		return
+	}
+
+	rules := []ModerationRule{}
+	for _, dbRule := range dbRules {
+		rules = append(rules, databaseModerationRuleToModerationRule(dbRule))
+	}
+
+	respondWithJSON(writer, http.StatusOK, rules)
+}
+
+func (cfg *apiConfig) handlerCreateModerationRule(writer http.ResponseWriter, req *http.Request) {
+	if _, ok := cfg.requireRole(writer, req, RoleAdmin); !ok {
+		return
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	var ruleReq moderationRuleRequest
+	if err := decoder.Decode(&ruleReq); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't decode parameters: " + err.Error())
+		return
+	}
+
+	_, err := moderation.Compile(moderation.Rule{Kind: ruleReq.Kind, Pattern: ruleReq.Pattern, Action: ruleReq.Action})
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid rule: " + err.Error())
+		return
+	}
+
+	dbRule, err := cfg.db.CreateModerationRule(req.Context(), database.CreateModerationRuleParams{
+		Kind: ruleReq.Kind,
+		Pattern: ruleReq.Pattern,
+		Action: ruleReq.Action,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't create moderation rule: " + err.Error())
+		return
+	}
+
+	if err := cfg.loadModerationRules(req.Context()); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't reload moderation rules: " + err.Error())
+		return
+	}
+
+	respondWithJSON(writer, http.StatusCreated, databaseModerationRuleToModerationRule(dbRule))
+}
+
+func (cfg *apiConfig) handlerUpdateModerationRule(writer http.ResponseWriter, req *http.Request) {
+	ruleID, err := uuid.Parse(req.PathValue("ruleID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid rule ID: " + err.Error())
+		return
+	}
+
+	if _, ok := cfg.requireRole(writer, req, RoleAdmin); !ok {
+		return
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	var ruleReq moderationRuleRequest
+	if err := decoder.Decode(&ruleReq); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't decode parameters: " + err.Error())
+		return
+	}
+
+	_, err = moderation.Compile(moderation.Rule{Kind: ruleReq.Kind, Pattern: ruleReq.Pattern, Action: ruleReq.Action})
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid rule: " + err.Error())
+		return
+	}
+
+	dbRule, err := cfg.db.UpdateModerationRule(req.Context(), database.UpdateModerationRuleParams{
+		Kind: ruleReq.Kind,
+		Pattern: ruleReq.Pattern,
+		Action: ruleReq.Action,
+		ID: ruleID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(writer, http.StatusNotFound, "Moderation rule not found")
+			return
+		}
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't update moderation rule: " + err.Error())
+		return
+	}
+
+	if err := cfg.loadModerationRules(req.Context()); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't reload moderation rules: " + err.Error())
+		return
+	}
+
+	respondWithJSON(writer, http.StatusOK, databaseModerationRuleToModerationRule(dbRule))
+}
+
+func (cfg *apiConfig) handlerDeleteModerationRule(writer http.ResponseWriter, req *http.Request) {
+	ruleID, err := uuid.Parse(req.PathValue("ruleID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid rule ID: " + err.Error())
+		return
+	}
+
+	if _, ok := cfg.requireRole(writer, req, RoleAdmin); !ok {
+		return
+	}
+
+	deleted, err := cfg.db.DeleteModerationRule(req.Context(), ruleID)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't delete moderation rule: " + err.Error())
+		return
+	}
+	if deleted == 0 {
+		respondWithError(writer, http.StatusNotFound, "Moderation rule not found")
+		return
+	}
+
+	if err := cfg.loadModerationRules(req.Context()); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't reload moderation rules: " + err.Error())
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+// handlerGetHeldChirps lists chirps a hold rule has kept out of sight,
+// oldest first, so moderators work through them in the order they came in.
+func (cfg *apiConfig) handlerGetHeldChirps(writer http.ResponseWriter, req *http.Request) {
+	moderator, ok := cfg.requireRole(writer, req, RoleModerator, RoleAdmin)
+	if !ok {
+		return
+	}
+
+	page, err := pagination.ParseForwardParams(req.URL.Query())
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid pagination parameters: " + err.Error())
+		return
+	}
+
+	dbChirps, err := cfg.db.GetHeldChirps(req.Context(), database.GetHeldChirpsParams{
+		CursorCreatedAt: page.Cursor.NullTime(),
+		CursorID: page.Cursor.NullID(),
+		Limit: page.Limit + 1,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't retrieve chirps: " + err.Error())
+		return
+	}
+
+	dbChirps, next, _ := pagination.Page(dbChirps, page.Limit, page.Cursor, func(chirp database.Chirp) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: chirp.CreatedAt, ID: chirp.ID}
+	})
+	if link := pagination.LinkHeader(req.URL, next, ""); link != "" {
+		writer.Header().Set("Link", link)
+	}
+
+	chirps, err := cfg.hydrateChirps(req.Context(), dbChirps, uuid.NullUUID{UUID: moderator.ID, Valid: true})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't load chirps: " + err.Error())
+		return
+	}
+
+	respondWithJSON(writer, http.StatusOK, chirps)
+}
+
+// handlerApproveHeldChirp releases a held chirp. Whoever it replied to
+// hears about it now rather than when it was posted.
+func (cfg *apiConfig) handlerApproveHeldChirp(writer http.ResponseWriter, req *http.Request) {
+	chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid chirp ID: " + err.Error())
+		return
+	}
+
+	moderator, ok := cfg.requireRole(writer, req, RoleModerator, RoleAdmin)
+	if !ok {
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't start transaction: " + err.Error())
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	dbChirp, err := qtx.ApproveHeldChirp(req.Context(), chirpID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(writer, http.StatusNotFound, "Held chirp not found")
+			return
+		}
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't approve chirp: " + err.Error())
+		return
+	}
+
+	if dbChirp.ParentID.Valid {
+		parent, err := qtx.GetChirpByIDForUpdate(req.Context(), dbChirp.ParentID.UUID)
+		if err == nil && !parent.TombstonedAt.Valid {
+			if err := notifyReply(req.Context(), qtx, parent, dbChirp); err != nil {
+				respondWithError(writer, http.StatusInternalServerError, "Couldn't create notification: " + err.Error())
+				return
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't approve chirp: " + err.Error())
+		return
+	}
+
+	chirps, err := cfg.hydrateChirps(req.Context(), []database.Chirp{dbChirp}, uuid.NullUUID{UUID: moderator.ID, Valid: true})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't load chirp: " + err.Error())
+		return
+	}
+
+	respondWithJSON(writer, http.StatusOK, chirps[0])
+}
+
+// handlerRejectHeldChirp moves a held chirp to the trash and tells the
+// author why. Like any moderator removal it's never purged, and its author
+// can't restore it.
+func (cfg *apiConfig) handlerRejectHeldChirp(writer http.ResponseWriter, req *http.Request) {
+	type rejectRequest struct {
+		Reason string `json:"reason"`
+	}
+
+	chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid chirp ID: " + err.Error())
+		return
+	}
+
+	moderator, ok := cfg.requireRole(writer, req, RoleModerator, RoleAdmin)
+	if !ok {
+		return
+	}
+
+	// the reason is optional, so an empty body is fine
+	decoder := json.NewDecoder(req.Body)
+	var rejectReq rejectRequest
+	if err := decoder.Decode(&rejectReq); err != nil && !errors.Is(err, io.EOF) {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't decode parameters: " + err.Error())
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't start transaction: " + err.Error())
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	dbChirp, err := qtx.GetChirpByIDForUpdate(req.Context(), chirpID)
+	if err != nil || !dbChirp.HeldForReview || dbChirp.TombstonedAt.Valid {
+		respondWithError(writer, http.StatusNotFound, "Held chirp not found")
+		return
+	}
+
+	if err := trashChirp(req.Context(), qtx, dbChirp, moderator.ID); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't remove chirp: " + err.Error())
+		return
+	}
+
+	reason := rejectReq.Reason
+	if reason == "" {
+		reason = "rejected by a moderator"
+	}
+	err = notify(req.Context(), qtx, dbChirp.UserID, NotificationChirpRemoved, chirpRemovedPayload{
+		ChirpID: dbChirp.ID,
+		Reason: reason,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't create notification: " + err.Error())
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't remove chirp: " + err.Error())
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func databaseModerationRuleToModerationRule(dbRule database.ModerationRule) ModerationRule {
+	return ModerationRule{
+		ID: dbRule.ID,
+		CreatedAt: dbRule.CreatedAt,
+		UpdatedAt: dbRule.UpdatedAt,
+		Kind: dbRule.Kind,
+		Pattern: dbRule.Pattern,
+		Action: dbRule.Action,
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/philipreese/chirpy-go/internal/auth"
+	"github.com/philipreese/chirpy-go/internal/database"
+	"github.com/philipreese/chirpy-go/internal/pagination"
+)
+
+type Notification struct {
+	ID        uuid.UUID       `json:"id"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+	ReadAt    *time.Time      `json:"read_at"`
+}
+
+func (cfg *apiConfig) handlerGetNotifications(writer http.ResponseWriter, req *http.Request) {
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't get bearer token: " + err.Error())
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	query := req.URL.Query()
+	page, err := pagination.ParseForwardParams(query)
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid pagination parameters: " + err.Error())
+		return
+	}
+
+	dbNotifications, err := cfg.db.GetNotifications(req.Context(), database.GetNotificationsParams{
+		UserID: userID,
+		UnreadOnly: query.Get("unread") == "true",
+		CursorCreatedAt: page.Cursor.NullTime(),
+		CursorID: page.Cursor.NullID(),
+		Limit: page.Limit + 1,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't retrieve notifications: " + err.Error())
+		return
+	}
+
+	dbNotifications, next, _ := pagination.Page(dbNotifications, page.Limit, page.Cursor, func(notification database.Notification) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: notification.CreatedAt, ID: notification.ID}
+	})
+	if link := pagination.LinkHeader(req.URL, next, ""); link != "" {
+		writer.Header().Set("Link", link)
+	}
+
+	notifications := []Notification{}
+	for _, dbNotification := range dbNotifications {
+		notification := Notification{
+			ID: dbNotification.ID,
+			Type: dbNotification.Type,
+			Payload: dbNotification.Payload,
+			CreatedAt: dbNotification.CreatedAt,
+		}
+		if dbNotification.ReadAt.Valid {
+			notification.ReadAt = &dbNotification.ReadAt.Time
+		}
+		notifications = append(notifications, notification)
+	}
+
+	respondWithJSON(writer, http.StatusOK, notifications)
+}
+
+func (cfg *apiConfig) handlerGetUnreadNotificationCount(writer http.ResponseWriter, req *http.Request) {
+	type unreadResponse struct {
+		UnreadCount int64 `json:"unread_count"`
+	}
+
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't get bearer token: " + err.Error())
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	count, err := cfg.db.CountUnreadNotifications(req.Context(), userID)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't count notifications: " + err.Error())
+		return
+	}
+
+	respondWithJSON(writer, http.StatusOK, unreadResponse{UnreadCount: count})
+}
+
+func (cfg *apiConfig) handlerMarkNotificationRead(writer http.ResponseWriter, req *http.Request) {
+	notificationID, err := uuid.Parse(req.PathValue("notificationID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid notification ID: " + err.Error())
+		return
+	}
+
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't get bearer token: " + err.Error())
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	rows, err := cfg.db.MarkNotificationRead(req.Context(), database.MarkNotificationReadParams{
+		ID: notificationID,
+		UserID: userID,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't mark notification read: " + err.Error())
+		return
+	}
+	if rows == 0 {
+		respondWithError(writer, http.StatusNotFound, "Couldn't get notification")
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerMarkAllNotificationsRead(writer http.ResponseWriter, req *http.Request) {
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't get bearer token: " + err.Error())
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	if err := cfg.db.MarkAllNotificationsRead(req.Context(), userID); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't mark notifications read: " + err.Error())
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/philipreese/chirpy-go/internal/auth"
+	"github.com/philipreese/chirpy-go/internal/database"
+)
+
+const (
+	minPollOptions      = 2
+	maxPollOptions      = 4
+	maxPollOptionLength = 50
+	maxPollDuration     = 7 * 24 * time.Hour
+)
+
+// Poll is the state of a chirp's poll as one viewer sees it. Votes and
+// TotalVotes stay null until the viewer has voted or the poll has closed,
+// so nobody can see which way it's going before making up their mind.
+type Poll struct {
+	ClosesAt       time.Time     `json:"closes_at"`
+	Closed         bool          `json:"closed"`
+	Options        []PollOption  `json:"options"`
+	TotalVotes     *int64        `json:"total_votes"`
+	VotedOptionID  uuid.NullUUID `json:"voted_option_id"`
+	ResultsVisible bool          `json:"results_visible"`
+}
+
+type PollOption struct {
+	ID    uuid.UUID `json:"id"`
+	Text  string    `json:"text"`
+	Votes *int64    `json:"votes"`
+}
+
+type pollRequest struct {
+	Options  []string  `json:"options"`
+	ClosesAt time.Time `json:"closes_at"`
+}
+
+// validatePoll checks a poll sent with a new chirp, returning its options
+// trimmed and moderated the same way as a chirp body, and whether any of
+// them should hold the chirp for review.
+func (cfg *apiConfig) validatePoll(poll pollRequest) ([]string, bool, error) {
+	if len(poll.Options) < minPollOptions || len(poll.Options) > maxPollOptions {
+		return nil, false, fmt.Errorf("poll must have between %d and %d options", minPollOptions, maxPollOptions)
+	}
+
+	now := time.Now()
+	if !poll.ClosesAt.After(now) {
+		return nil, false, errors.New("poll must close in the future")
+	}
+	if poll.ClosesAt.After(now.Add(maxPollDuration)) {
+		return nil, false, errors.New("poll can stay open for at most 7 days")
+	}
+
+	options := make([]string, 0, len(poll.Options))
+	held := false
+	seen := map[string]bool{}
+	for _, option := range poll.Options {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			return nil, false, errors.New("poll options can't be empty")
+		}
+		if utf8.RuneCountInString(option) > maxPollOptionLength {
+			return nil, false, fmt.Errorf("poll options must be at most %d characters", maxPollOptionLength)
+		}
+		if seen[strings.ToLower(option)] {
+			return nil, false, errors.New("poll options must be different")
+		}
+		seen[strings.ToLower(option)] = true
+
+		cleaned, optionHeld, rejected := cfg.moderateText(option)
+		if rejected {
+			return nil, false, errors.New("poll option contains a term that isn't allowed")
+		}
+		held = held || optionHeld
+		options = append(options, cleaned)
+	}
+
+	return options, held, nil
+}
+
+// createPoll attaches a validated poll to a chirp that is being created in
+// the same transaction.
+func createPoll(ctx context.Context, q *database.Queries, chirpID uuid.UUID, options []string, closesAt time.Time) error {
+	err := q.CreatePoll(ctx, database.CreatePollParams{
+		ChirpID: chirpID,
+		ClosesAt: closesAt.UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return q.CreatePollOptions(ctx, database.CreatePollOptionsParams{
+		ChirpID: chirpID,
+		Texts: options,
+	})
+}
+
+// handlerVotePoll records the caller's vote. The vote row's primary key
+// allows one per user, and it's written in the same transaction as the
+// option's tally, so a second or concurrent vote changes neither.
+func (cfg *apiConfig) handlerVotePoll(writer http.ResponseWriter, req *http.Request) {
+	type voteRequest struct {
+		OptionID uuid.UUID `json:"option_id"`
+	}
+
+	chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid chirp ID: " + err.Error())
+		return
+	}
+
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't get bearer token: " + err.Error())
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	var voteReq voteRequest
+	if err := decoder.Decode(&voteReq); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't decode parameters: " + err.Error())
+		return
+	}
+
+	viewerID := uuid.NullUUID{UUID: userID, Valid: true}
+	dbChirp, err := cfg.db.GetChirpByID(req.Context(), database.GetChirpByIDParams{
+		ID: chirpID,
+		ViewerID: viewerID,
+	})
+	if err != nil || dbChirp.TombstonedAt.Valid {
+		respondWithError(writer, http.StatusNotFound, "Chirp not found")
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't start transaction: " + err.Error())
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	poll, err := qtx.GetPoll(req.Context(), chirpID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(writer, http.StatusNotFound, "Chirp has no poll")
+			return
+		}
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't retrieve poll: " + err.Error())
+		return
+	}
+
+	if !poll.ClosesAt.After(time.Now().UTC()) {
+		respondWithError(writer, http.StatusBadRequest, "Couldn't vote: the poll has closed")
+		return
+	}
+
+	isOption, err := qtx.PollOptionExists(req.Context(), database.PollOptionExistsParams{
+		ID: voteReq.OptionID,
+		ChirpID: chirpID,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't retrieve poll: " + err.Error())
+		return
+	}
+	if !isOption {
+		respondWithError(writer, http.StatusBadRequest, "Couldn't vote: not an option in this poll")
+		return
+	}
+
+	voted, err := qtx.CastPollVote(req.Context(), database.CastPollVoteParams{
+		ChirpID: chirpID,
+		UserID: userID,
+		OptionID: voteReq.OptionID,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't record vote: " + err.Error())
+		return
+	}
+	if voted == 0 {
+		respondWithError(writer, http.StatusConflict, "You have already voted in this poll")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't record vote: " + err.Error())
+		return
+	}
+
+	chirp, err := cfg.buildChirp(req.Context(), dbChirp, viewerID)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't load chirp: " + err.Error())
+		return
+	}
+
+	respondWithJSON(writer, http.StatusOK, chirp)
+}
+
+// loadPolls builds the polls for a list of chirps as viewerID sees them,
+// keyed by chirp ID. Chirps without a poll are left out.
+func (cfg *apiConfig) loadPolls(ctx context.Context, chirpIDs []uuid.UUID, viewerID uuid.NullUUID) (map[uuid.UUID]*Poll, error) {
+	polls := map[uuid.UUID]*Poll{}
+
+	optionRows, err := cfg.db.GetPollOptions(ctx, chirpIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(optionRows) == 0 {
+		return polls, nil
+	}
+
+	// votes only count for viewers who can see the voter, the same as their
+	// likes, so the tallies are counted from the votes each time
+	countRows, err := cfg.db.GetPollVoteCounts(ctx, database.GetPollVoteCountsParams{
+		ChirpIds: chirpIDs,
+		ViewerID: viewerID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	counts := map[uuid.UUID]int64{}
+	for _, row := range countRows {
+		counts[row.OptionID] = row.VoteCount
+	}
+
+	votes := map[uuid.UUID]uuid.UUID{}
+	if viewerID.Valid {
+		voteRows, err := cfg.db.GetPollVotes(ctx, database.GetPollVotesParams{
+			UserID: viewerID.UUID,
+			ChirpIds: chirpIDs,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range voteRows {
+			votes[row.ChirpID] = row.OptionID
+		}
+	}
+
+	now := time.Now().UTC()
+	totals := map[uuid.UUID]int64{}
+	for _, row := range optionRows {
+		chirpID := row.PollOption.ChirpID
+		poll, ok := polls[chirpID]
+		if !ok {
+			optionID, voted := votes[chirpID]
+			poll = &Poll{
+				ClosesAt: row.ClosesAt,
+				Closed: !row.ClosesAt.After(now),
+				Options: []PollOption{},
+				VotedOptionID: uuid.NullUUID{UUID: optionID, Valid: voted},
+			}
+			poll.ResultsVisible = poll.Closed || voted
+			polls[chirpID] = poll
+		}
+
+		option := PollOption{ID: row.PollOption.ID, Text: row.PollOption.Text}
+		if poll.ResultsVisible {
+			count := counts[row.PollOption.ID]
+			option.Votes = &count
+			totals[chirpID] += count
+		}
+		poll.Options = append(poll.Options, option)
+	}
+
+	for chirpID, poll := range polls {
+		if poll.ResultsVisible {
+			total := totals[chirpID]
+			poll.TotalVotes = &total
+		}
+	}
+
+	return polls, nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/philipreese/chirpy-go/internal/database"
+	"github.com/philipreese/chirpy-go/internal/pagination"
+)
+
+// Profile is the public view of a user. It must never carry the email
+// address or anything else only the account holder should see.
+type Profile struct {
+	ID          uuid.UUID `json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	Handle      string    `json:"handle"`
+	DisplayName string    `json:"display_name"`
+	Bio         string    `json:"bio"`
+	Location    string    `json:"location"`
+	IsChirpyRed bool      `json:"is_chirpy_red"`
+}
+
+func (cfg *apiConfig) handlerGetProfile(writer http.ResponseWriter, req *http.Request) {
+	type profileResponse struct {
+		Profile
+		FollowerCount  int64 `json:"follower_count"`
+		FollowingCount int64 `json:"following_count"`
+	}
+
+	dbUser, err := cfg.db.GetUserByHandle(req.Context(), req.PathValue("handle"))
+	if err != nil {
+		respondWithError(writer, http.StatusNotFound, "Couldn't get user")
+		return
+	}
+
+	counts, err := cfg.db.GetFollowCounts(req.Context(), dbUser.ID)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't count follows: " + err.Error())
+		return
+	}
+
+	respondWithJSON(writer, http.StatusOK, profileResponse{
+		Profile: databaseUserToProfile(dbUser),
+		FollowerCount: counts.FollowerCount,
+		FollowingCount: counts.FollowingCount,
+	})
+}
+
+func (cfg *apiConfig) handlerGetProfileChirps(writer http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+
+	page, err := pagination.ParseParams(query)
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid pagination parameters: " + err.Error())
+		return
+	}
+
+	viewerID, err := cfg.getViewerID(req)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	dbUser, err := cfg.db.GetUserByHandle(req.Context(), req.PathValue("handle"))
+	if err != nil {
+		respondWithError(writer, http.StatusNotFound, "Couldn't get user")
+		return
+	}
+
+	// newest first unless asked otherwise, which is how a profile reads
+	descending := query.Get("sort") != "asc"
+	if page.Cursor != nil && page.Cursor.Direction == pagination.Prev {
+		descending = !descending
+	}
+
+	authorID := uuid.NullUUID{UUID: dbUser.ID, Valid: true}
+	entries, err := cfg.listChirps(req.Context(), authorID, viewerID, page.Cursor, descending, page.Limit+1)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't retrieve chirps: " + err.Error())
+		return
+	}
+
+	entries, next, prev := pagination.Page(entries, page.Limit, page.Cursor, feedPosition)
+	if link := pagination.LinkHeader(req.URL, next, prev); link != "" {
+		writer.Header().Set("Link", link)
+	}
+
+	chirps, err := cfg.buildFeed(req.Context(), entries, viewerID)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't load chirps: " + err.Error())
+		return
+	}
+
+	respondWithJSON(writer, http.StatusOK, chirps)
+}
+
+func databaseUserToProfile(dbUser database.User) Profile {
+	return Profile{
+		ID: dbUser.ID,
+		CreatedAt: dbUser.CreatedAt,
+		Handle: dbUser.Handle,
+		DisplayName: dbUser.DisplayName,
+		Bio: dbUser.Bio,
+		Location: dbUser.Location,
+		IsChirpyRed: dbUser.IsChirpyRed,
+	}
+}
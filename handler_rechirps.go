@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/philipreese/chirpy-go/internal/auth"
+	"github.com/philipreese/chirpy-go/internal/database"
+	"github.com/philipreese/chirpy-go/internal/pagination"
+)
+
+// feedEntry is a chirp as it appears in a timeline or on a profile. Each
+// chirp shows up once, at the time it was posted or, if someone whose
+// chirps are in the list has rechirped it since, at the latest rechirp.
+type feedEntry struct {
+	Chirp       database.Chirp
+	ActiveAt    time.Time
+	RechirpedBy uuid.NullUUID
+}
+
+func feedPosition(entry feedEntry) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: entry.ActiveAt, ID: entry.Chirp.ID}
+}
+
+// chirpsToFeed places chirps in a list that has no rechirps at the time
+// they were posted.
+func chirpsToFeed(dbChirps []database.Chirp) []feedEntry {
+	entries := make([]feedEntry, 0, len(dbChirps))
+	for _, dbChirp := range dbChirps {
+		entries = append(entries, feedEntry{Chirp: dbChirp, ActiveAt: dbChirp.CreatedAt})
+	}
+	return entries
+}
+
+// buildFeed builds the chirps in a timeline or profile as viewerID should
+// see them, marking the ones that are there because they were rechirped.
+func (cfg *apiConfig) buildFeed(ctx context.Context, entries []feedEntry, viewerID uuid.NullUUID) ([]Chirp, error) {
+	dbChirps := make([]database.Chirp, 0, len(entries))
+	for _, entry := range entries {
+		dbChirps = append(dbChirps, entry.Chirp)
+	}
+
+	chirps, err := cfg.buildChirps(ctx, dbChirps, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, entry := range entries {
+		if entry.RechirpedBy.Valid {
+			chirps[i].RechirpedBy = &entry.RechirpedBy.UUID
+			chirps[i].RechirpedAt = &entry.ActiveAt
+		}
+	}
+
+	return chirps, nil
+}
+
+func (cfg *apiConfig) handlerRechirp(writer http.ResponseWriter, req *http.Request) {
+	chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid chirp ID: " + err.Error())
+		return
+	}
+
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't get bearer token: " + err.Error())
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	chirp, err := cfg.db.GetChirpByID(req.Context(), database.GetChirpByIDParams{
+		ID: chirpID,
+		ViewerID: uuid.NullUUID{UUID: userID, Valid: true},
+	})
+	if err != nil || chirp.TombstonedAt.Valid {
+		respondWithError(writer, http.StatusNotFound, "Couldn't get chirp")
+		return
+	}
+
+	err = cfg.db.CreateRechirp(req.Context(), database.CreateRechirpParams{
+		UserID: userID,
+		ChirpID: chirp.ID,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't rechirp: " + err.Error())
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerUndoRechirp(writer http.ResponseWriter, req *http.Request) {
+	chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid chirp ID: " + err.Error())
+		return
+	}
+
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't get bearer token: " + err.Error())
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	err = cfg.db.DeleteRechirp(req.Context(), database.DeleteRechirpParams{
+		UserID: userID,
+		ChirpID: chirpID,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't undo rechirp: " + err.Error())
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
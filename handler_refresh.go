@@ -34,6 +34,18 @@ func (cfg *apiConfig) handlerRefresh(writer http.ResponseWriter, req *http.Reque
 		return
 	}
 
+	// suspending a user revokes their refresh tokens, but check anyway in
+	// case one was issued while the suspension was being applied
+	user, err := cfg.db.GetUserByID(req.Context(), refreshToken.UserID)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't find user: " + err.Error())
+		return
+	}
+	if isSuspended(user) {
+		respondWithError(writer, http.StatusForbidden, suspensionMessage(user))
+		return
+	}
+
 	token, err := auth.MakeJWT(refreshToken.UserID, cfg.tokenSecret, time.Hour)
 	if err != nil {
 		respondWithError(writer, http.StatusUnauthorized, "Failed to create token: " + err.Error())
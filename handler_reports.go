@@ -0,0 +1,639 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/philipreese/chirpy-go/internal/auth"
+	"github.com/philipreese/chirpy-go/internal/database"
+	"github.com/philipreese/chirpy-go/internal/pagination"
+)
+
+const maxReportCommentLength = 1000
+
+var reportReasons = []string{"spam", "harassment", "hate", "violence", "sexual", "misinformation", "other"}
+
+// Report case statuses. A case is opened by its first report, claimed by
+// the moderator working on it, and resolved one of three ways.
+const (
+	ReportCaseOpen     = "open"
+	ReportCaseClaimed  = "claimed"
+	ReportCaseResolved = "resolved"
+)
+
+// Ways of resolving a report case, also used as the action in its history.
+const (
+	ReportResolutionRemoved   = "removed"
+	ReportResolutionWarned    = "warned"
+	ReportResolutionDismissed = "dismissed"
+)
+
+type Report struct {
+	ID         uuid.UUID `json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	CaseID     uuid.UUID `json:"case_id"`
+	ReporterID uuid.UUID `json:"reporter_id"`
+	Reason     string    `json:"reason"`
+	Comment    string    `json:"comment"`
+}
+
+// ReportCase groups every report about the same chirp, or about the same
+// account when no chirp is given, until a moderator resolves it. Reports
+// made after that open a new case.
+type ReportCase struct {
+	ID             uuid.UUID         `json:"id"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+	ChirpID        uuid.NullUUID     `json:"chirp_id"`
+	ReportedUserID uuid.UUID         `json:"reported_user_id"`
+	Status         string            `json:"status"`
+	ClaimedBy      uuid.NullUUID     `json:"claimed_by"`
+	Resolution     string            `json:"resolution"`
+	ResolutionNote string            `json:"resolution_note"`
+	ResolvedAt     *time.Time        `json:"resolved_at"`
+	ReportCount    int64             `json:"report_count"`
+	Chirp          *Chirp            `json:"chirp,omitempty"`
+	Reports        []Report          `json:"reports,omitempty"`
+	Events         []ReportCaseEvent `json:"events,omitempty"`
+}
+
+type ReportCaseEvent struct {
+	ID        uuid.UUID     `json:"id"`
+	CreatedAt time.Time     `json:"created_at"`
+	ActorID   uuid.NullUUID `json:"actor_id"`
+	Action    string        `json:"action"`
+	Note      string        `json:"note"`
+}
+
+func (cfg *apiConfig) handlerCreateReport(writer http.ResponseWriter, req *http.Request) {
+	type reportRequest struct {
+		ChirpID uuid.NullUUID `json:"chirp_id"`
+		UserID  uuid.NullUUID `json:"user_id"`
+		Reason  string        `json:"reason"`
+		Comment string        `json:"comment"`
+	}
+
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't get bearer token: " + err.Error())
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	var reportReq reportRequest
+	if err := decoder.Decode(&reportReq); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't decode parameters: " + err.Error())
+		return
+	}
+
+	if reportReq.ChirpID.Valid == reportReq.UserID.Valid {
+		respondWithError(writer, http.StatusBadRequest, "Invalid report: give either a chirp_id or a user_id")
+		return
+	}
+	if !slices.Contains(reportReasons, reportReq.Reason) {
+		respondWithError(writer, http.StatusBadRequest, "Invalid report: reason must be spam, harassment, hate, violence, sexual, misinformation or other")
+		return
+	}
+	if utf8.RuneCountInString(reportReq.Comment) > maxReportCommentLength {
+		respondWithError(writer, http.StatusBadRequest, fmt.Sprintf("Invalid report: comment must be at most %d characters", maxReportCommentLength))
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't start transaction: " + err.Error())
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	var reportCase database.ReportCase
+	if reportReq.ChirpID.Valid {
+		// you can only report chirps you can see
+		dbChirp, err := qtx.GetChirpByID(req.Context(), database.GetChirpByIDParams{
+			ID: reportReq.ChirpID.UUID,
+			ViewerID: uuid.NullUUID{UUID: userID, Valid: true},
+		})
+		if err != nil || dbChirp.TombstonedAt.Valid {
+			respondWithError(writer, http.StatusNotFound, "Chirp not found")
+			return
+		}
+		if dbChirp.UserID == userID {
+			respondWithError(writer, http.StatusBadRequest, "Invalid report: you can't report your own chirp")
+			return
+		}
+
+		reportCase, err = qtx.OpenChirpReportCase(req.Context(), database.OpenChirpReportCaseParams{
+			ChirpID: reportReq.ChirpID,
+			ReportedUserID: dbChirp.UserID,
+		})
+		if err != nil {
+			respondWithError(writer, http.StatusInternalServerError, "Couldn't create report: " + err.Error())
+			return
+		}
+	} else {
+		if reportReq.UserID.UUID == userID {
+			respondWithError(writer, http.StatusBadRequest, "Invalid report: you can't report yourself")
+			return
+		}
+		if _, err := qtx.GetUserByID(req.Context(), reportReq.UserID.UUID); err != nil {
+			respondWithError(writer, http.StatusNotFound, "User not found")
+			return
+		}
+
+		reportCase, err = qtx.OpenUserReportCase(req.Context(), reportReq.UserID.UUID)
+		if err != nil {
+			respondWithError(writer, http.StatusInternalServerError, "Couldn't create report: " + err.Error())
+			return
+		}
+	}
+
+	dbReport, err := qtx.CreateReport(req.Context(), database.CreateReportParams{
+		CaseID: reportCase.ID,
+		ReporterID: userID,
+		Reason: reportReq.Reason,
+		Comment: reportReq.Comment,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(writer, http.StatusConflict, "You have already reported this")
+			return
+		}
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't create report: " + err.Error())
+		return
+	}
+
+	err = qtx.CreateReportCaseEvent(req.Context(), database.CreateReportCaseEventParams{
+		CaseID: reportCase.ID,
+		ActorID: uuid.NullUUID{UUID: userID, Valid: true},
+		Action: "reported",
+		Note: reportReq.Reason,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't record report: " + err.Error())
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't create report: " + err.Error())
+		return
+	}
+
+	respondWithJSON(writer, http.StatusCreated, databaseReportToReport(dbReport))
+}
+
+func (cfg *apiConfig) handlerGetReportCases(writer http.ResponseWriter, req *http.Request) {
+	if _, ok := cfg.requireRole(writer, req, RoleModerator, RoleAdmin); !ok {
+		return
+	}
+
+	status := req.URL.Query().Get("status")
+	if status == "" {
+		status = ReportCaseOpen
+	}
+	if status != ReportCaseOpen && status != ReportCaseClaimed && status != ReportCaseResolved {
+		respondWithError(writer, http.StatusBadRequest, "Invalid status: must be open, claimed or resolved")
+		return
+	}
+
+	page, err := pagination.ParseForwardParams(req.URL.Query())
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid pagination parameters: " + err.Error())
+		return
+	}
+
+	rows, err := cfg.db.GetReportCases(req.Context(), database.GetReportCasesParams{
+		Status: status,
+		CursorCreatedAt: page.Cursor.NullTime(),
+		CursorID: page.Cursor.NullID(),
+		Limit: page.Limit + 1,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't retrieve reports: " + err.Error())
+		return
+	}
+
+	rows, next, _ := pagination.Page(rows, page.Limit, page.Cursor, func(row database.GetReportCasesRow) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: row.ReportCase.CreatedAt, ID: row.ReportCase.ID}
+	})
+	if link := pagination.LinkHeader(req.URL, next, ""); link != "" {
+		writer.Header().Set("Link", link)
+	}
+
+	cases := []ReportCase{}
+	for _, row := range rows {
+		reportCase := databaseReportCaseToReportCase(row.ReportCase)
+		reportCase.ReportCount = row.ReportCount
+		cases = append(cases, reportCase)
+	}
+
+	respondWithJSON(writer, http.StatusOK, cases)
+}
+
+// handlerGetReportCase returns a case with all of its reports, its history
+// and, for chirp reports, the chirp itself if it's still there.
+func (cfg *apiConfig) handlerGetReportCase(writer http.ResponseWriter, req *http.Request) {
+	caseID, err := uuid.Parse(req.PathValue("caseID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid report ID: " + err.Error())
+		return
+	}
+
+	moderator, ok := cfg.requireRole(writer, req, RoleModerator, RoleAdmin)
+	if !ok {
+		return
+	}
+
+	dbCase, err := cfg.db.GetReportCase(req.Context(), caseID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(writer, http.StatusNotFound, "Report not found")
+			return
+		}
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't retrieve report: " + err.Error())
+		return
+	}
+
+	reportCase, err := cfg.loadReportCase(req, dbCase, moderator.ID)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't load report: " + err.Error())
+		return
+	}
+
+	respondWithJSON(writer, http.StatusOK, reportCase)
+}
+
+// handlerClaimReportCase assigns a case to the caller so two moderators
+// don't act on the same reports. Claiming a case you already hold is fine.
+func (cfg *apiConfig) handlerClaimReportCase(writer http.ResponseWriter, req *http.Request) {
+	caseID, err := uuid.Parse(req.PathValue("caseID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid report ID: " + err.Error())
+		return
+	}
+
+	moderator, ok := cfg.requireRole(writer, req, RoleModerator, RoleAdmin)
+	if !ok {
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't start transaction: " + err.Error())
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	dbCase, ok := lockReportCase(writer, req, qtx, caseID)
+	if !ok {
+		return
+	}
+	if dbCase.ClaimedBy.Valid && dbCase.ClaimedBy.UUID != moderator.ID {
+		respondWithError(writer, http.StatusConflict, "Report has been claimed by another moderator")
+		return
+	}
+
+	if !dbCase.ClaimedBy.Valid {
+		dbCase, err = qtx.ClaimReportCase(req.Context(), database.ClaimReportCaseParams{
+			ClaimedBy: uuid.NullUUID{UUID: moderator.ID, Valid: true},
+			ID: caseID,
+		})
+		if err != nil {
+			respondWithError(writer, http.StatusInternalServerError, "Couldn't claim report: " + err.Error())
+			return
+		}
+
+		err = qtx.CreateReportCaseEvent(req.Context(), database.CreateReportCaseEventParams{
+			CaseID: caseID,
+			ActorID: uuid.NullUUID{UUID: moderator.ID, Valid: true},
+			Action: "claimed",
+		})
+		if err != nil {
+			respondWithError(writer, http.StatusInternalServerError, "Couldn't record claim: " + err.Error())
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't claim report: " + err.Error())
+		return
+	}
+
+	reportCase, err := cfg.loadReportCase(req, dbCase, moderator.ID)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't load report: " + err.Error())
+		return
+	}
+
+	respondWithJSON(writer, http.StatusOK, reportCase)
+}
+
+// handlerReleaseReportCase hands a claimed case back to the queue. Admins
+// can release anyone's claim, for when a moderator has moved on.
+func (cfg *apiConfig) handlerReleaseReportCase(writer http.ResponseWriter, req *http.Request) {
+	caseID, err := uuid.Parse(req.PathValue("caseID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid report ID: " + err.Error())
+		return
+	}
+
+	moderator, ok := cfg.requireRole(writer, req, RoleModerator, RoleAdmin)
+	if !ok {
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't start transaction: " + err.Error())
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	dbCase, ok := lockReportCase(writer, req, qtx, caseID)
+	if !ok {
+		return
+	}
+	if !dbCase.ClaimedBy.Valid {
+		respondWithError(writer, http.StatusConflict, "Report hasn't been claimed")
+		return
+	}
+	if dbCase.ClaimedBy.UUID != moderator.ID && moderator.Role != RoleAdmin {
+		respondWithError(writer, http.StatusForbidden, "Report has been claimed by another moderator")
+		return
+	}
+
+	dbCase, err = qtx.ReleaseReportCase(req.Context(), caseID)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't release report: " + err.Error())
+		return
+	}
+
+	err = qtx.CreateReportCaseEvent(req.Context(), database.CreateReportCaseEventParams{
+		CaseID: caseID,
+		ActorID: uuid.NullUUID{UUID: moderator.ID, Valid: true},
+		Action: "released",
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't record release: " + err.Error())
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't release report: " + err.Error())
+		return
+	}
+
+	reportCase, err := cfg.loadReportCase(req, dbCase, moderator.ID)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't load report: " + err.Error())
+		return
+	}
+
+	respondWithJSON(writer, http.StatusOK, reportCase)
+}
+
+// handlerResolveReportCase closes a case the caller has claimed. Removing
+// the chirp moves it to the trash for good, kept as evidence: it's never
+// purged and its author can't restore it, and they're told why. A warning
+// only sends the author a notification; dismissing does neither.
+func (cfg *apiConfig) handlerResolveReportCase(writer http.ResponseWriter, req *http.Request) {
+	type resolveRequest struct {
+		Resolution string `json:"resolution"`
+		Note       string `json:"note"`
+	}
+
+	caseID, err := uuid.Parse(req.PathValue("caseID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid report ID: " + err.Error())
+		return
+	}
+
+	moderator, ok := cfg.requireRole(writer, req, RoleModerator, RoleAdmin)
+	if !ok {
+		return
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	var resolveReq resolveRequest
+	if err := decoder.Decode(&resolveReq); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't decode parameters: " + err.Error())
+		return
+	}
+
+	switch resolveReq.Resolution {
+	case ReportResolutionRemoved, ReportResolutionWarned, ReportResolutionDismissed:
+	default:
+		respondWithError(writer, http.StatusBadRequest, "Invalid resolution: must be removed, warned or dismissed")
+		return
+	}
+	if utf8.RuneCountInString(resolveReq.Note) > maxReportCommentLength {
+		respondWithError(writer, http.StatusBadRequest, fmt.Sprintf("Invalid resolution: note must be at most %d characters", maxReportCommentLength))
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't start transaction: " + err.Error())
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	dbCase, ok := lockReportCase(writer, req, qtx, caseID)
+	if !ok {
+		return
+	}
+	if !dbCase.ClaimedBy.Valid || dbCase.ClaimedBy.UUID != moderator.ID {
+		respondWithError(writer, http.StatusConflict, "Claim the report before resolving it")
+		return
+	}
+
+	switch resolveReq.Resolution {
+	case ReportResolutionRemoved:
+		if !dbCase.ChirpID.Valid {
+			respondWithError(writer, http.StatusBadRequest, "Invalid resolution: only reports about a chirp can remove it")
+			return
+		}
+
+		dbChirp, err := qtx.GetChirpByIDForUpdate(req.Context(), dbCase.ChirpID.UUID)
+		if err != nil || dbChirp.TombstonedAt.Valid {
+			respondWithError(writer, http.StatusConflict, "Chirp has already been deleted")
+			return
+		}
+
+		if err := trashChirp(req.Context(), qtx, dbChirp, moderator.ID); err != nil {
+			respondWithError(writer, http.StatusInternalServerError, "Couldn't remove chirp: " + err.Error())
+			return
+		}
+
+		reason := resolveReq.Note
+		if reason == "" {
+			reason = "removed after being reported"
+		}
+		err = notify(req.Context(), qtx, dbChirp.UserID, NotificationChirpRemoved, chirpRemovedPayload{
+			ChirpID: dbChirp.ID,
+			Reason: reason,
+		})
+		if err != nil {
+			respondWithError(writer, http.StatusInternalServerError, "Couldn't create notification: " + err.Error())
+			return
+		}
+
+	case ReportResolutionWarned:
+		err := notify(req.Context(), qtx, dbCase.ReportedUserID, NotificationModerationWarning, moderationWarningPayload{
+			ChirpID: dbCase.ChirpID,
+			Reason: resolveReq.Note,
+		})
+		if err != nil {
+			respondWithError(writer, http.StatusInternalServerError, "Couldn't create notification: " + err.Error())
+			return
+		}
+	}
+
+	dbCase, err = qtx.ResolveReportCase(req.Context(), database.ResolveReportCaseParams{
+		Resolution: resolveReq.Resolution,
+		ResolutionNote: resolveReq.Note,
+		ID: caseID,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't resolve report: " + err.Error())
+		return
+	}
+
+	err = qtx.CreateReportCaseEvent(req.Context(), database.CreateReportCaseEventParams{
+		CaseID: caseID,
+		ActorID: uuid.NullUUID{UUID: moderator.ID, Valid: true},
+		Action: resolveReq.Resolution,
+		Note: resolveReq.Note,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't record resolution: " + err.Error())
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't resolve report: " + err.Error())
+		return
+	}
+
+	reportCase, err := cfg.loadReportCase(req, dbCase, moderator.ID)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't load report: " + err.Error())
+		return
+	}
+
+	respondWithJSON(writer, http.StatusOK, reportCase)
+}
+
+// lockReportCase loads a case for update and makes sure it's still open to
+// being worked on. It writes the error response itself, so callers should
+// just return when ok is false.
+func lockReportCase(writer http.ResponseWriter, req *http.Request, q *database.Queries, caseID uuid.UUID) (database.ReportCase, bool) {
+	dbCase, err := q.GetReportCaseForUpdate(req.Context(), caseID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(writer, http.StatusNotFound, "Report not found")
+			return database.ReportCase{}, false
+		}
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't retrieve report: " + err.Error())
+		return database.ReportCase{}, false
+	}
+
+	if dbCase.Status == ReportCaseResolved {
+		respondWithError(writer, http.StatusConflict, "Report has already been resolved")
+		return database.ReportCase{}, false
+	}
+
+	return dbCase, true
+}
+
+func (cfg *apiConfig) loadReportCase(req *http.Request, dbCase database.ReportCase, moderatorID uuid.UUID) (ReportCase, error) {
+	reportCase := databaseReportCaseToReportCase(dbCase)
+
+	dbReports, err := cfg.db.GetCaseReports(req.Context(), dbCase.ID)
+	if err != nil {
+		return ReportCase{}, err
+	}
+	reportCase.Reports = []Report{}
+	for _, dbReport := range dbReports {
+		reportCase.Reports = append(reportCase.Reports, databaseReportToReport(dbReport))
+	}
+	reportCase.ReportCount = int64(len(dbReports))
+
+	dbEvents, err := cfg.db.GetReportCaseEvents(req.Context(), dbCase.ID)
+	if err != nil {
+		return ReportCase{}, err
+	}
+	reportCase.Events = []ReportCaseEvent{}
+	for _, dbEvent := range dbEvents {
+		reportCase.Events = append(reportCase.Events, ReportCaseEvent{
+			ID: dbEvent.ID,
+			CreatedAt: dbEvent.CreatedAt,
+			ActorID: dbEvent.ActorID,
+			Action: dbEvent.Action,
+			Note: dbEvent.Note,
+		})
+	}
+
+	if dbCase.ChirpID.Valid {
+		// moderators see the chirp however it's been posted, and still see
+		// it once it's been deleted, until it's purged
+		dbChirp, err := cfg.db.GetChirpIncludingDeleted(req.Context(), dbCase.ChirpID.UUID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return ReportCase{}, err
+		}
+		if err == nil {
+			chirps, err := cfg.hydrateChirps(req.Context(), []database.Chirp{dbChirp}, uuid.NullUUID{UUID: moderatorID, Valid: true})
+			if err != nil {
+				return ReportCase{}, err
+			}
+			reportCase.Chirp = &chirps[0]
+		}
+	}
+
+	return reportCase, nil
+}
+
+func databaseReportCaseToReportCase(dbCase database.ReportCase) ReportCase {
+	reportCase := ReportCase{
+		ID: dbCase.ID,
+		CreatedAt: dbCase.CreatedAt,
+		UpdatedAt: dbCase.UpdatedAt,
+		ChirpID: dbCase.ChirpID,
+		ReportedUserID: dbCase.ReportedUserID,
+		Status: dbCase.Status,
+		ClaimedBy: dbCase.ClaimedBy,
+		Resolution: dbCase.Resolution,
+		ResolutionNote: dbCase.ResolutionNote,
+	}
+	if dbCase.ResolvedAt.Valid {
+		reportCase.ResolvedAt = &dbCase.ResolvedAt.Time
+	}
+	return reportCase
+}
+
+func databaseReportToReport(dbReport database.Report) Report {
+	return Report{
+		ID: dbReport.ID,
+		CreatedAt: dbReport.CreatedAt,
+		CaseID: dbReport.CaseID,
+		ReporterID: dbReport.ReporterID,
+		Reason: dbReport.Reason,
+		Comment: dbReport.Comment,
+	}
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/philipreese/chirpy-go/internal/auth"
+	"github.com/philipreese/chirpy-go/internal/database"
+	"github.com/philipreese/chirpy-go/internal/pagination"
+)
+
+type ScheduledChirp struct {
+	ID             uuid.UUID         `json:"id"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+	PublishAt      time.Time         `json:"publish_at"`
+	Body           string            `json:"body"`
+	UserID         uuid.UUID         `json:"user_id"`
+	Visibility     string            `json:"visibility"`
+	ContentWarning string            `json:"content_warning"`
+	Sensitive      bool              `json:"sensitive"`
+	InReplyTo      uuid.NullUUID     `json:"in_reply_to"`
+	QuotedChirpID  uuid.NullUUID     `json:"quoted_chirp_id"`
+	Media          []MediaAttachment `json:"media"`
+}
+
+// scheduleChirp stores an already validated chirp to be published later by
+// the background publisher. Any media is reserved for it straight away so
+// the uploads can't be attached anywhere else in the meantime.
+func (cfg *apiConfig) scheduleChirp(writer http.ResponseWriter, req *http.Request, params database.CreateScheduledChirpParams, mediaIDs []uuid.UUID) {
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't start transaction: " + err.Error())
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	dbScheduled, err := qtx.CreateScheduledChirp(req.Context(), params)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't schedule chirp: " + err.Error())
+		return
+	}
+
+	if len(mediaIDs) > 0 {
+		reserved, err := qtx.ReserveMediaForScheduledChirp(req.Context(), database.ReserveMediaForScheduledChirpParams{
+			ScheduledChirpID: uuid.NullUUID{UUID: dbScheduled.ID, Valid: true},
+			MediaIds: mediaIDs,
+			UserID: params.UserID,
+		})
+		if err != nil {
+			respondWithError(writer, http.StatusInternalServerError, "Couldn't attach media: " + err.Error())
+			return
+		}
+		if reserved != int64(len(mediaIDs)) {
+			respondWithError(writer, http.StatusBadRequest, "Invalid chirp: media must be your own unattached uploads")
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't schedule chirp: " + err.Error())
+		return
+	}
+
+	scheduled, err := cfg.buildScheduledChirps(req.Context(), []database.ScheduledChirp{dbScheduled})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't load scheduled chirp: " + err.Error())
+		return
+	}
+
+	respondWithJSON(writer, http.StatusCreated, scheduled[0])
+}
+
+func (cfg *apiConfig) handlerGetScheduledChirps(writer http.ResponseWriter, req *http.Request) {
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't get bearer token: " + err.Error())
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	page, err := pagination.ParseForwardParams(req.URL.Query())
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid pagination parameters: " + err.Error())
+		return
+	}
+
+	dbScheduled, err := cfg.db.GetScheduledChirps(req.Context(), database.GetScheduledChirpsParams{
+		UserID: userID,
+		CursorPublishAt: page.Cursor.NullTime(),
+		CursorID: page.Cursor.NullID(),
+		Limit: page.Limit + 1,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't retrieve scheduled chirps: " + err.Error())
+		return
+	}
+
+	dbScheduled, next, _ := pagination.Page(dbScheduled, page.Limit, page.Cursor, func(scheduled database.ScheduledChirp) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: scheduled.PublishAt, ID: scheduled.ID}
+	})
+	if link := pagination.LinkHeader(req.URL, next, ""); link != "" {
+		writer.Header().Set("Link", link)
+	}
+
+	scheduled, err := cfg.buildScheduledChirps(req.Context(), dbScheduled)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't load scheduled chirps: " + err.Error())
+		return
+	}
+
+	respondWithJSON(writer, http.StatusOK, scheduled)
+}
+
+func (cfg *apiConfig) handlerRescheduleChirp(writer http.ResponseWriter, req *http.Request) {
+	type rescheduleRequest struct {
+		PublishAt *time.Time `json:"publish_at"`
+	}
+
+	scheduledID, err := uuid.Parse(req.PathValue("scheduledChirpID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid scheduled chirp ID: " + err.Error())
+		return
+	}
+
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't get bearer token: " + err.Error())
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	var rescheduleReq rescheduleRequest
+	if err := decoder.Decode(&rescheduleReq); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't decode parameters: " + err.Error())
+		return
+	}
+
+	if rescheduleReq.PublishAt == nil || !rescheduleReq.PublishAt.After(time.Now()) {
+		respondWithError(writer, http.StatusBadRequest, "Invalid schedule: publish_at must be in the future")
+		return
+	}
+
+	// if the publisher has already claimed the chirp this waits for it and
+	// then finds nothing, so a chirp can't be rescheduled once it's out
+	dbScheduled, err := cfg.db.RescheduleChirp(req.Context(), database.RescheduleChirpParams{
+		PublishAt: rescheduleReq.PublishAt.UTC(),
+		ID: scheduledID,
+		UserID: userID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(writer, http.StatusNotFound, "Scheduled chirp not found")
+			return
+		}
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't reschedule chirp: " + err.Error())
+		return
+	}
+
+	scheduled, err := cfg.buildScheduledChirps(req.Context(), []database.ScheduledChirp{dbScheduled})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't load scheduled chirp: " + err.Error())
+		return
+	}
+
+	respondWithJSON(writer, http.StatusOK, scheduled[0])
+}
+
+func (cfg *apiConfig) handlerCancelScheduledChirp(writer http.ResponseWriter, req *http.Request) {
+	scheduledID, err := uuid.Parse(req.PathValue("scheduledChirpID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid scheduled chirp ID: " + err.Error())
+		return
+	}
+
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't get bearer token: " + err.Error())
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	// reserved media is released by the foreign key, so the uploads can be
+	// used again
+	deleted, err := cfg.db.CancelScheduledChirp(req.Context(), database.CancelScheduledChirpParams{
+		ID: scheduledID,
+		UserID: userID,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't cancel scheduled chirp: " + err.Error())
+		return
+	}
+	if deleted == 0 {
+		respondWithError(writer, http.StatusNotFound, "Scheduled chirp not found")
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) buildScheduledChirps(ctx context.Context, dbScheduled []database.ScheduledChirp) ([]ScheduledChirp, error) {
+	scheduledIDs := make([]uuid.UUID, 0, len(dbScheduled))
+	for _, dbChirp := range dbScheduled {
+		scheduledIDs = append(scheduledIDs, dbChirp.ID)
+	}
+
+	media := map[uuid.UUID][]MediaAttachment{}
+	if len(scheduledIDs) > 0 {
+		mediaRows, err := cfg.db.GetScheduledChirpMedia(ctx, scheduledIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range mediaRows {
+			media[row.ScheduledChirpID.UUID] = append(media[row.ScheduledChirpID.UUID], cfg.databaseMediaToMedia(row))
+		}
+	}
+
+	scheduled := make([]ScheduledChirp, 0, len(dbScheduled))
+	for _, dbChirp := range dbScheduled {
+		chirp := ScheduledChirp{
+			ID: dbChirp.ID,
+			CreatedAt: dbChirp.CreatedAt,
+			UpdatedAt: dbChirp.UpdatedAt,
+			PublishAt: dbChirp.PublishAt,
+			Body: dbChirp.Body,
+			UserID: dbChirp.UserID,
+			Visibility: dbChirp.Visibility,
+			ContentWarning: dbChirp.ContentWarning,
+			Sensitive: dbChirp.Sensitive,
+			InReplyTo: dbChirp.ParentID,
+			QuotedChirpID: dbChirp.QuotedChirpID,
+			Media: media[dbChirp.ID],
+		}
+		if chirp.Media == nil {
+			chirp.Media = []MediaAttachment{}
+		}
+		scheduled = append(scheduled, chirp)
+	}
+
+	return scheduled, nil
+}
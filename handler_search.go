@@ -0,0 +1,89 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/philipreese/chirpy-go/internal/database"
+	"github.com/philipreese/chirpy-go/internal/pagination"
+	"github.com/philipreese/chirpy-go/internal/search"
+)
+
+type chirpSearchResult struct {
+	Chirp
+	Rank    float32 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+func (cfg *apiConfig) handlerSearchChirps(writer http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+
+	tsQuery, err := search.ParseQuery(query.Get("q"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid search query: " + err.Error())
+		return
+	}
+
+	viewerID, err := cfg.getViewerID(req)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	page, err := pagination.ParseForwardParams(query)
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid pagination parameters: " + err.Error())
+		return
+	}
+
+	params := database.SearchChirpsParams{
+		Query: tsQuery,
+		ViewerID: viewerID,
+		CursorCreatedAt: page.Cursor.NullTime(),
+		CursorID: page.Cursor.NullID(),
+		Limit: page.Limit + 1,
+	}
+	if page.Cursor != nil {
+		params.CursorRank = sql.NullFloat64{Float64: float64(page.Cursor.Rank), Valid: true}
+	}
+
+	rows, err := cfg.db.SearchChirps(req.Context(), params)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't search chirps: " + err.Error())
+		return
+	}
+
+	rows, next, _ := pagination.Page(rows, page.Limit, page.Cursor, func(row database.SearchChirpsRow) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: row.Chirp.CreatedAt, ID: row.Chirp.ID, Rank: row.Rank}
+	})
+	if link := pagination.LinkHeader(req.URL, next, ""); link != "" {
+		writer.Header().Set("Link", link)
+	}
+
+	dbChirps := make([]database.Chirp, 0, len(rows))
+	for _, row := range rows {
+		dbChirps = append(dbChirps, row.Chirp)
+	}
+
+	chirps, err := cfg.buildChirps(req.Context(), dbChirps, viewerID)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't load chirps: " + err.Error())
+		return
+	}
+
+	results := []chirpSearchResult{}
+	for i, row := range rows {
+		result := chirpSearchResult{
+			Chirp: chirps[i],
+			Rank: row.Rank,
+			Snippet: row.Snippet,
+		}
+		// the snippet is cut from the body, so it goes wherever the body goes
+		if chirps[i].BodyWithheld {
+			result.Snippet = ""
+		}
+		results = append(results, result)
+	}
+
+	respondWithJSON(writer, http.StatusOK, results)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/philipreese/chirpy-go/internal/database"
+)
+
+// handlerShadowBanUser hides everything a user posts from everyone but
+// themselves, without telling them. Their chirps, likes and rechirps are
+// left out of every list, lookup and count anyone else sees, while their
+// own view of the site doesn't change, so a spammer has no reason to start
+// again under a new account.
+func (cfg *apiConfig) handlerShadowBanUser(writer http.ResponseWriter, req *http.Request) {
+	cfg.setShadowBanned(writer, req, true)
+}
+
+func (cfg *apiConfig) handlerLiftShadowBan(writer http.ResponseWriter, req *http.Request) {
+	cfg.setShadowBanned(writer, req, false)
+}
+
+func (cfg *apiConfig) setShadowBanned(writer http.ResponseWriter, req *http.Request, shadowBanned bool) {
+	userID, err := uuid.Parse(req.PathValue("userID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid user ID: " + err.Error())
+		return
+	}
+
+	admin, ok := cfg.requireRole(writer, req, RoleAdmin)
+	if !ok {
+		return
+	}
+
+	if userID == admin.ID {
+		respondWithError(writer, http.StatusBadRequest, "You can't shadow-ban yourself")
+		return
+	}
+
+	target, err := cfg.db.GetUserByID(req.Context(), userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(writer, http.StatusNotFound, "User not found")
+			return
+		}
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't retrieve user: " + err.Error())
+		return
+	}
+	if target.Role == RoleAdmin {
+		respondWithError(writer, http.StatusForbidden, "Admins can't be shadow-banned")
+		return
+	}
+
+	_, err = cfg.db.SetUserShadowBanned(req.Context(), database.SetUserShadowBannedParams{
+		ShadowBanned: shadowBanned,
+		ID: userID,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't update user: " + err.Error())
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/philipreese/chirpy-go/internal/database"
+	"github.com/philipreese/chirpy-go/internal/spam"
+)
+
+// maxSpamCandidates caps how many recent look-alike chirps are compared
+// against a new one. Past this many the verdict is decided anyway.
+const maxSpamCandidates = 200
+
+// scoreSpam weighs a chirp body against what has been posted in the spam
+// window: near-duplicates from its author and from anyone else, other
+// chirps sharing its links, and how quickly a new author has been posting.
+// An edit passes the chirp being edited so it isn't compared to itself.
+func (cfg *apiConfig) scoreSpam(ctx context.Context, q *database.Queries, userID uuid.UUID, body string, editing uuid.NullUUID) (spam.Verdict, error) {
+	user, err := q.GetUserByID(ctx, userID)
+	if err != nil {
+		return spam.Verdict{}, err
+	}
+
+	now := time.Now().UTC()
+	since := now.Add(-cfg.spam.Window)
+	signals := spam.Signals{
+		Fingerprint: spam.NewFingerprint(body),
+		AccountAge: now.Sub(user.CreatedAt),
+	}
+
+	candidates, err := q.GetSimilarFingerprints(ctx, database.GetSimilarFingerprintsParams{
+		Since: since,
+		ExcludingChirpID: editing,
+		ContentHash: signals.Fingerprint.Hash,
+		Shingles: signals.Fingerprint.Shingles,
+		Limit: maxSpamCandidates,
+	})
+	if err != nil {
+		return spam.Verdict{}, err
+	}
+	for _, candidate := range candidates {
+		signals.Candidates = append(signals.Candidates, spam.Earlier{
+			SameAuthor: candidate.UserID == userID,
+			AuthorID: candidate.UserID.String(),
+			Hash: candidate.ContentHash,
+			Shingles: candidate.Shingles,
+		})
+	}
+
+	if len(signals.Fingerprint.Links) > 0 {
+		linkPosts, err := q.CountLinkPosts(ctx, database.CountLinkPostsParams{
+			Since: since,
+			ExcludingChirpID: editing,
+			Links: signals.Fingerprint.Links,
+		})
+		if err != nil {
+			return spam.Verdict{}, err
+		}
+		signals.LinkPosts = int(linkPosts)
+	}
+
+	recentPosts, err := q.CountUserChirpsSince(ctx, database.CountUserChirpsSinceParams{
+		UserID: userID,
+		Since: since,
+		ExcludingChirpID: editing,
+	})
+	if err != nil {
+		return spam.Verdict{}, err
+	}
+	signals.RecentPosts = int(recentPosts)
+
+	return cfg.spam.Score(signals), nil
+}
+
+// screenSpam scores a chirp about to be posted, or the new body of one being
+// edited, and responds for it if it can't be: a rejected chirp is refused, and a throttled one is turned away
+// with a Retry-After of the spam window, by when whatever set it off will
+// have aged out. It reports whether the chirp should be held for review,
+// and false for ok once it has responded.
+func (cfg *apiConfig) screenSpam(writer http.ResponseWriter, req *http.Request, q *database.Queries, userID uuid.UUID, body string, editing uuid.NullUUID) (held bool, ok bool) {
+	verdict, err := cfg.scoreSpam(req.Context(), q, userID, body, editing)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't check chirp for spam: " + err.Error())
+		return false, false
+	}
+
+	switch verdict.Action {
+	case spam.ActionReject:
+		respondWithError(writer, http.StatusBadRequest, "Invalid chirp: it looks like spam (" + strings.Join(verdict.Reasons, ", ") + ")")
+		return false, false
+	case spam.ActionThrottle:
+		writer.Header().Set("Retry-After", strconv.Itoa(int(cfg.spam.Window.Seconds())))
+		respondWithError(writer, http.StatusTooManyRequests, "Slow down: " + strings.Join(verdict.Reasons, ", "))
+		return false, false
+	}
+
+	return verdict.Action == spam.ActionHold, true
+}
+
+// saveFingerprint records what later chirps are compared against to spot
+// spam. It's called again when a chirp is edited, after the new body has
+// been screened, so later chirps are compared against what it now says.
+func saveFingerprint(ctx context.Context, q *database.Queries, dbChirp database.Chirp) error {
+	fingerprint := spam.NewFingerprint(dbChirp.Body)
+	return q.SaveChirpFingerprint(ctx, database.SaveChirpFingerprintParams{
+		ChirpID: dbChirp.ID,
+		UserID: dbChirp.UserID,
+		CreatedAt: dbChirp.CreatedAt,
+		ContentHash: fingerprint.Hash,
+		Shingles: fingerprint.Shingles,
+		Links: fingerprint.Links,
+	})
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/philipreese/chirpy-go/internal/database"
+)
+
+const maxSuspensionReasonLength = 1000
+
+// Suspension is how admins see a user's suspension. A null Until means the
+// suspension is permanent.
+type Suspension struct {
+	UserID      uuid.UUID  `json:"user_id"`
+	SuspendedAt time.Time  `json:"suspended_at"`
+	Until       *time.Time `json:"until"`
+	Reason      string     `json:"reason"`
+	HideChirps  bool       `json:"hide_chirps"`
+}
+
+// isSuspended reports whether a user is currently suspended. Temporary
+// suspensions end on their own once their end date has passed.
+func isSuspended(user database.User) bool {
+	if !user.SuspendedAt.Valid {
+		return false
+	}
+	return !user.SuspendedUntil.Valid || user.SuspendedUntil.Time.After(time.Now().UTC())
+}
+
+// suspensionMessage explains to a suspended user why they've been refused.
+func suspensionMessage(user database.User) string {
+	message := "Account suspended"
+	if user.SuspendedUntil.Valid {
+		message += " until " + user.SuspendedUntil.Time.Format(time.RFC3339)
+	}
+	if user.SuspensionReason != "" {
+		message += ": " + user.SuspensionReason
+	}
+	return message
+}
+
+// handlerSuspendUser suspends a user, or replaces their current suspension.
+// Their refresh tokens are revoked in the same step, so they're signed out
+// once their current access token expires.
+func (cfg *apiConfig) handlerSuspendUser(writer http.ResponseWriter, req *http.Request) {
+	type suspendRequest struct {
+		Until      *time.Time `json:"until"`
+		Reason     string     `json:"reason"`
+		HideChirps bool       `json:"hide_chirps"`
+	}
+
+	userID, err := uuid.Parse(req.PathValue("userID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid user ID: " + err.Error())
+		return
+	}
+
+	admin, ok := cfg.requireRole(writer, req, RoleAdmin)
+	if !ok {
+		return
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	var suspendReq suspendRequest
+	if err := decoder.Decode(&suspendReq); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't decode parameters: " + err.Error())
+		return
+	}
+
+	if suspendReq.Until != nil && !suspendReq.Until.After(time.Now()) {
+		respondWithError(writer, http.StatusBadRequest, "Invalid suspension: until must be in the future")
+		return
+	}
+	if utf8.RuneCountInString(suspendReq.Reason) > maxSuspensionReasonLength {
+		respondWithError(writer, http.StatusBadRequest, fmt.Sprintf("Invalid suspension: reason must be at most %d characters", maxSuspensionReasonLength))
+		return
+	}
+
+	if userID == admin.ID {
+		respondWithError(writer, http.StatusBadRequest, "Invalid suspension: you can't suspend yourself")
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't start transaction: " + err.Error())
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	target, err := qtx.GetUserByID(req.Context(), userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(writer, http.StatusNotFound, "User not found")
+			return
+		}
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't retrieve user: " + err.Error())
+		return
+	}
+	if target.Role == RoleAdmin {
+		respondWithError(writer, http.StatusForbidden, "Admins can't be suspended")
+		return
+	}
+
+	until := sql.NullTime{}
+	if suspendReq.Until != nil {
+		until = sql.NullTime{Time: suspendReq.Until.UTC(), Valid: true}
+	}
+	user, err := qtx.SuspendUser(req.Context(), database.SuspendUserParams{
+		SuspendedUntil: until,
+		SuspensionReason: suspendReq.Reason,
+		SuspensionHidesChirps: suspendReq.HideChirps,
+		ID: userID,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't suspend user: " + err.Error())
+		return
+	}
+
+	if err := qtx.RevokeUserRefreshTokens(req.Context(), userID); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't revoke refresh tokens: " + err.Error())
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't suspend user: " + err.Error())
+		return
+	}
+
+	respondWithJSON(writer, http.StatusOK, databaseUserToSuspension(user))
+}
+
+// handlerUnsuspendUser lifts a suspension early. The user has to log in
+// again, since their refresh tokens were revoked when they were suspended.
+func (cfg *apiConfig) handlerUnsuspendUser(writer http.ResponseWriter, req *http.Request) {
+	userID, err := uuid.Parse(req.PathValue("userID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid user ID: " + err.Error())
+		return
+	}
+
+	if _, ok := cfg.requireRole(writer, req, RoleAdmin); !ok {
+		return
+	}
+
+	lifted, err := cfg.db.UnsuspendUser(req.Context(), userID)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't lift suspension: " + err.Error())
+		return
+	}
+	if lifted == 0 {
+		respondWithError(writer, http.StatusNotFound, "User isn't suspended")
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func databaseUserToSuspension(user database.User) Suspension {
+	suspension := Suspension{
+		UserID: user.ID,
+		SuspendedAt: user.SuspendedAt.Time,
+		Reason: user.SuspensionReason,
+		HideChirps: user.SuspensionHidesChirps,
+	}
+	if user.SuspendedUntil.Valid {
+		suspension.Until = &user.SuspendedUntil.Time
+	}
+	return suspension
+}
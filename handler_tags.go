@@ -0,0 +1,120 @@
+package main
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/philipreese/chirpy-go/internal/database"
+	"github.com/philipreese/chirpy-go/internal/hashtags"
+	"github.com/philipreese/chirpy-go/internal/pagination"
+)
+
+const (
+	defaultTrendingWindow = 24 * time.Hour
+	maxTrendingWindow     = 7 * 24 * time.Hour
+	defaultTrendingLimit  = 10
+	maxTrendingLimit      = 50
+)
+
+type TrendingTag struct {
+	Tag   string  `json:"tag"`
+	Uses  int64   `json:"uses"`
+	Score float64 `json:"score"`
+}
+
+func (cfg *apiConfig) handlerGetTagChirps(writer http.ResponseWriter, req *http.Request) {
+	tag := hashtags.Normalize(req.PathValue("tag"))
+	if tag == "" {
+		respondWithError(writer, http.StatusBadRequest, "Invalid tag")
+		return
+	}
+
+	viewerID, err := cfg.getViewerID(req)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	page, err := pagination.ParseForwardParams(req.URL.Query())
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid pagination parameters: " + err.Error())
+		return
+	}
+
+	dbChirps, err := cfg.db.GetTagChirps(req.Context(), database.GetTagChirpsParams{
+		Name: tag,
+		ViewerID: viewerID,
+		CursorCreatedAt: page.Cursor.NullTime(),
+		CursorID: page.Cursor.NullID(),
+		Limit: page.Limit + 1,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't retrieve chirps: " + err.Error())
+		return
+	}
+
+	dbChirps, next, _ := pagination.Page(dbChirps, page.Limit, page.Cursor, chirpPosition)
+	if link := pagination.LinkHeader(req.URL, next, ""); link != "" {
+		writer.Header().Set("Link", link)
+	}
+
+	chirps, err := cfg.buildChirps(req.Context(), dbChirps, viewerID)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't load chirps: " + err.Error())
+		return
+	}
+
+	respondWithJSON(writer, http.StatusOK, chirps)
+}
+
+// handlerGetTrendingTags ranks the tags used within the window. Each use
+// counts for less the older it is, halving every quarter of the window, so
+// a tag picking up speed beats one that was busy yesterday but has gone
+// quiet.
+func (cfg *apiConfig) handlerGetTrendingTags(writer http.ResponseWriter, req *http.Request) {
+	window := defaultTrendingWindow
+	if windowStr := req.URL.Query().Get("window"); windowStr != "" {
+		var err error
+		window, err = time.ParseDuration(windowStr)
+		if err != nil || window <= 0 {
+			respondWithError(writer, http.StatusBadRequest, "Invalid window: must be a positive duration such as 6h")
+			return
+		}
+		window = min(window, maxTrendingWindow)
+	}
+
+	limit := defaultTrendingLimit
+	if limitStr := req.URL.Query().Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			respondWithError(writer, http.StatusBadRequest, "Invalid limit: must be a positive number")
+			return
+		}
+		limit = min(limit, maxTrendingLimit)
+	}
+
+	halfLife := window / 4
+	dbTags, err := cfg.db.GetTrendingTags(req.Context(), database.GetTrendingTagsParams{
+		DecaySeconds: halfLife.Seconds() / math.Ln2,
+		WindowSeconds: window.Seconds(),
+		Limit: int32(limit),
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't retrieve trending tags: " + err.Error())
+		return
+	}
+
+	tags := []TrendingTag{}
+	for _, dbTag := range dbTags {
+		tags = append(tags, TrendingTag{
+			Tag: dbTag.Name,
+			Uses: dbTag.Uses,
+			Score: dbTag.Score,
+		})
+	}
+
+	respondWithJSON(writer, http.StatusOK, tags)
+}
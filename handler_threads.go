@@ -0,0 +1,90 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/philipreese/chirpy-go/internal/database"
+)
+
+const (
+	defaultThreadDepth = 5
+	maxThreadDepth     = 20
+	maxThreadSize      = 500
+)
+
+// ChirpThread is a chirp together with the replies below it. Replies cut off
+// by the depth limit still show up in reply_count, and can be fetched by
+// asking for the thread of the chirp they belong to.
+type ChirpThread struct {
+	Chirp
+	Replies []*ChirpThread `json:"replies"`
+}
+
+func (cfg *apiConfig) handlerGetChirpThread(writer http.ResponseWriter, req *http.Request) {
+	chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid chirp ID: " + err.Error())
+		return
+	}
+
+	viewerID, err := cfg.getViewerID(req)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	depth := defaultThreadDepth
+	if depthStr := req.URL.Query().Get("depth"); depthStr != "" {
+		depth, err = strconv.Atoi(depthStr)
+		if err != nil || depth < 0 {
+			respondWithError(writer, http.StatusBadRequest, "Invalid depth: must be a non-negative number")
+			return
+		}
+		depth = min(depth, maxThreadDepth)
+	}
+
+	rows, err := cfg.db.GetChirpThread(req.Context(), database.GetChirpThreadParams{
+		RootID: chirpID,
+		ViewerID: viewerID,
+		MaxDepth: int32(depth),
+		Limit: maxThreadSize,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't retrieve thread: " + err.Error())
+		return
+	}
+
+	if len(rows) == 0 {
+		respondWithError(writer, http.StatusNotFound, "Couldn't get chirp")
+		return
+	}
+
+	dbChirps := make([]database.Chirp, 0, len(rows))
+	for _, row := range rows {
+		dbChirps = append(dbChirps, asTombstone(row.Chirp))
+	}
+
+	chirps, err := cfg.buildChirps(req.Context(), dbChirps, viewerID)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't load chirps: " + err.Error())
+		return
+	}
+
+	// rows come back ordered by depth, so every parent is in the map before
+	// any of its replies
+	root := &ChirpThread{Chirp: chirps[0], Replies: []*ChirpThread{}}
+	nodes := map[uuid.UUID]*ChirpThread{root.ID: root}
+	for _, chirp := range chirps[1:] {
+		parent, ok := nodes[chirp.InReplyTo.UUID]
+		if !ok {
+			continue
+		}
+		node := &ChirpThread{Chirp: chirp, Replies: []*ChirpThread{}}
+		parent.Replies = append(parent.Replies, node)
+		nodes[chirp.ID] = node
+	}
+
+	respondWithJSON(writer, http.StatusOK, root)
+}
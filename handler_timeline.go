@@ -0,0 +1,77 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/philipreese/chirpy-go/internal/auth"
+	"github.com/philipreese/chirpy-go/internal/database"
+	"github.com/philipreese/chirpy-go/internal/pagination"
+)
+
+// handlerGetTimeline lists chirps from the caller and everyone they follow,
+// and the chirps any of them have rechirped, newest first. Paging with a
+// prev cursor walks back towards newer chirps.
+func (cfg *apiConfig) handlerGetTimeline(writer http.ResponseWriter, req *http.Request) {
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't get bearer token: " + err.Error())
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	page, err := pagination.ParseParams(req.URL.Query())
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid pagination parameters: " + err.Error())
+		return
+	}
+
+	entries := []feedEntry{}
+	if page.Cursor != nil && page.Cursor.Direction == pagination.Prev {
+		rows, err := cfg.db.GetTimelineNewer(req.Context(), database.GetTimelineNewerParams{
+			UserID: userID,
+			CursorCreatedAt: page.Cursor.NullTime(),
+			CursorID: page.Cursor.NullID(),
+			Limit: page.Limit + 1,
+		})
+		if err != nil {
+			respondWithError(writer, http.StatusInternalServerError, "Couldn't retrieve timeline: " + err.Error())
+			return
+		}
+		for _, row := range rows {
+			entries = append(entries, feedEntry(row))
+		}
+	} else {
+		rows, err := cfg.db.GetTimeline(req.Context(), database.GetTimelineParams{
+			UserID: userID,
+			CursorCreatedAt: page.Cursor.NullTime(),
+			CursorID: page.Cursor.NullID(),
+			Limit: page.Limit + 1,
+		})
+		if err != nil {
+			respondWithError(writer, http.StatusInternalServerError, "Couldn't retrieve timeline: " + err.Error())
+			return
+		}
+		for _, row := range rows {
+			entries = append(entries, feedEntry(row))
+		}
+	}
+
+	entries, next, prev := pagination.Page(entries, page.Limit, page.Cursor, feedPosition)
+	if link := pagination.LinkHeader(req.URL, next, prev); link != "" {
+		writer.Header().Set("Link", link)
+	}
+
+	chirps, err := cfg.buildFeed(req.Context(), entries, uuid.NullUUID{UUID: userID, Valid: true})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't load chirps: " + err.Error())
+		return
+	}
+
+	respondWithJSON(writer, http.StatusOK, chirps)
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/philipreese/chirpy-go/internal/auth"
+	"github.com/philipreese/chirpy-go/internal/database"
+	"github.com/philipreese/chirpy-go/internal/pagination"
+)
+
+const (
+	trashPurgeInterval = time.Hour
+
+	// a chirp that fails to purge is passed over for trashPurgeRetryDelay so
+	// it doesn't hold up the ones behind it
+	trashPurgeRetryDelay = 24 * time.Hour
+)
+
+// TrashedChirp is a deleted chirp as its author and moderators see it,
+// until it's purged at PurgeAt. DeletedBy is the author if they deleted it
+// themselves and the moderator otherwise; chirps a moderator removed are
+// never purged, so they have no PurgeAt.
+type TrashedChirp struct {
+	Chirp
+	DeletedBy uuid.NullUUID `json:"deleted_by"`
+	PurgeAt   *time.Time    `json:"purge_at,omitempty"`
+}
+
+// trashChirp deletes a chirp into the trash. It drops out of every list and
+// lookup straight away but keeps its likes, tags, media and poll, so that
+// restoring it brings it back as it was. Only chirps their authors deleted
+// can be restored or are ever purged; a moderator's removals stay as they
+// are.
+func trashChirp(ctx context.Context, q *database.Queries, chirp database.Chirp, deletedBy uuid.UUID) error {
+	return q.TrashChirp(ctx, database.TrashChirpParams{
+		DeletedBy: uuid.NullUUID{UUID: deletedBy, Valid: true},
+		ID: chirp.ID,
+	})
+}
+
+// asTombstone shows a chirp in the trash the way threads show any deleted
+// chirp that has replies: in its place, with nothing of what it said.
+func asTombstone(dbChirp database.Chirp) database.Chirp {
+	if !dbChirp.DeletedAt.Valid {
+		return dbChirp
+	}
+
+	dbChirp.Body = ""
+	dbChirp.ContentWarning = ""
+	dbChirp.Sensitive = false
+	dbChirp.TombstonedAt = dbChirp.DeletedAt
+	dbChirp.DeletedAt = sql.NullTime{}
+	dbChirp.DeletedBy = uuid.NullUUID{}
+	return dbChirp
+}
+
+// handlerGetTrash lists the chirps the user has deleted that can still be
+// restored, most recently deleted first. Chirps removed by a moderator
+// aren't in it.
+func (cfg *apiConfig) handlerGetTrash(writer http.ResponseWriter, req *http.Request) {
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't get bearer token: " + err.Error())
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	page, err := pagination.ParseForwardParams(req.URL.Query())
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid pagination parameters: " + err.Error())
+		return
+	}
+
+	dbChirps, err := cfg.db.GetTrashedChirps(req.Context(), database.GetTrashedChirpsParams{
+		UserID: userID,
+		DeletedAfter: time.Now().UTC().Add(-cfg.trashRetention),
+		CursorCreatedAt: page.Cursor.NullTime(),
+		CursorID: page.Cursor.NullID(),
+		Limit: page.Limit + 1,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't retrieve trash: " + err.Error())
+		return
+	}
+
+	cfg.respondWithTrashedChirps(writer, req, dbChirps, page, userID)
+}
+
+// handlerRestoreChirp takes a chirp back out of the trash, as long as its
+// author deleted it and it hasn't been there longer than the retention
+// period.
+func (cfg *apiConfig) handlerRestoreChirp(writer http.ResponseWriter, req *http.Request) {
+	chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid chirp ID: " + err.Error())
+		return
+	}
+
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't get bearer token: " + err.Error())
+		return
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return
+	}
+
+	dbChirp, err := cfg.db.RestoreChirp(req.Context(), database.RestoreChirpParams{
+		ID: chirpID,
+		UserID: userID,
+		DeletedAfter: time.Now().UTC().Add(-cfg.trashRetention),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(writer, http.StatusNotFound, "Chirp not found in trash")
+			return
+		}
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't restore chirp: " + err.Error())
+		return
+	}
+
+	chirp, err := cfg.buildChirp(req.Context(), dbChirp, uuid.NullUUID{UUID: userID, Valid: true})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't load chirp: " + err.Error())
+		return
+	}
+
+	respondWithJSON(writer, http.StatusOK, chirp)
+}
+
+// handlerGetDeletedChirps lists every deleted chirp still waiting to be
+// purged, whoever deleted it, optionally for a single author.
+func (cfg *apiConfig) handlerGetDeletedChirps(writer http.ResponseWriter, req *http.Request) {
+	moderator, ok := cfg.requireRole(writer, req, RoleModerator, RoleAdmin)
+	if !ok {
+		return
+	}
+
+	query := req.URL.Query()
+
+	page, err := pagination.ParseForwardParams(query)
+	if err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid pagination parameters: " + err.Error())
+		return
+	}
+
+	var authorID uuid.NullUUID
+	if userIDStr := query.Get("user_id"); userIDStr != "" {
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			respondWithError(writer, http.StatusBadRequest, "Invalid user ID: " + err.Error())
+			return
+		}
+		authorID = uuid.NullUUID{UUID: userID, Valid: true}
+	}
+
+	dbChirps, err := cfg.db.GetDeletedChirps(req.Context(), database.GetDeletedChirpsParams{
+		UserID: authorID,
+		CursorCreatedAt: page.Cursor.NullTime(),
+		CursorID: page.Cursor.NullID(),
+		Limit: page.Limit + 1,
+	})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't retrieve chirps: " + err.Error())
+		return
+	}
+
+	cfg.respondWithTrashedChirps(writer, req, dbChirps, page, moderator.ID)
+}
+
+func (cfg *apiConfig) respondWithTrashedChirps(writer http.ResponseWriter, req *http.Request, dbChirps []database.Chirp, page pagination.Params, viewerID uuid.UUID) {
+	dbChirps, next, _ := pagination.Page(dbChirps, page.Limit, page.Cursor, func(chirp database.Chirp) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: chirp.DeletedAt.Time, ID: chirp.ID}
+	})
+	if link := pagination.LinkHeader(req.URL, next, ""); link != "" {
+		writer.Header().Set("Link", link)
+	}
+
+	chirps, err := cfg.hydrateChirps(req.Context(), dbChirps, uuid.NullUUID{UUID: viewerID, Valid: true})
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't load chirps: " + err.Error())
+		return
+	}
+
+	trashed := make([]TrashedChirp, 0, len(chirps))
+	for i, chirp := range chirps {
+		trashedChirp := TrashedChirp{
+			Chirp: chirp,
+			DeletedBy: dbChirps[i].DeletedBy,
+		}
+		if dbChirps[i].DeletedBy.Valid && dbChirps[i].DeletedBy.UUID == dbChirps[i].UserID {
+			purgeAt := dbChirps[i].DeletedAt.Time.Add(cfg.trashRetention)
+			trashedChirp.PurgeAt = &purgeAt
+		}
+		trashed = append(trashed, trashedChirp)
+	}
+
+	respondWithJSON(writer, http.StatusOK, trashed)
+}
+
+// purgeTrash purges chirps that have been in the trash longer than the
+// retention period every interval until ctx is cancelled. Like the
+// scheduled publisher, every server runs one of these and they skip chirps
+// another has claimed.
+func (cfg *apiConfig) purgeTrash(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			purged, err := cfg.purgeNextTrashedChirp(ctx)
+			if err != nil {
+				log.Printf("Couldn't purge deleted chirp: %v", err)
+				break
+			}
+			if !purged {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// purgeNextTrashedChirp deletes the chirp that has been in the trash the
+// longest for good, if it's been there past the retention period, and
+// reports false when there was nothing to purge. Chirps a moderator removed
+// are left alone: report cases point at them, and they're the evidence a
+// moderator needs to review an appeal or a repeat offender. A chirp that
+// can't be purged is marked as having failed and passed over, and false
+// comes back only if even that couldn't be done.
+func (cfg *apiConfig) purgeNextTrashedChirp(ctx context.Context) (bool, error) {
+	tx, err := cfg.dbConn.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	now := time.Now().UTC()
+	dbChirp, err := qtx.ClaimExpiredTrashedChirp(ctx, database.ClaimExpiredTrashedChirpParams{
+		DeletedAt: now.Add(-cfg.trashRetention),
+		PurgeFailedAt: now.Add(-trashPurgeRetryDelay),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	media, err := purgeTrashedChirp(ctx, qtx, dbChirp)
+	if err != nil {
+		log.Printf("Couldn't purge deleted chirp %s: %v", dbChirp.ID, err)
+		tx.Rollback()
+		if err := cfg.db.RecordChirpPurgeFailure(ctx, dbChirp.ID); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+
+	// the chirp is gone either way, so a file that won't delete is only
+	// wasted disk, not a failed purge
+	for _, dbMedia := range media {
+		cfg.blobs.Delete(ctx, dbMedia.StorageKey)
+		cfg.blobs.Delete(ctx, dbMedia.ThumbnailKey)
+	}
+
+	return true, nil
+}
+
+// purgeTrashedChirp deletes a trashed chirp, returning the media that was
+// attached to it so its files can be deleted once that's committed.
+func purgeTrashedChirp(ctx context.Context, q *database.Queries, dbChirp database.Chirp) ([]database.MediaAttachment, error) {
+	media, err := q.GetChirpMedia(ctx, []uuid.UUID{dbChirp.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := removeChirp(ctx, q, dbChirp); err != nil {
+		return nil, err
+	}
+
+	return media, nil
+}
@@ -1,27 +1,54 @@
 package main
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"regexp"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/philipreese/chirpy-go/internal/auth"
 	"github.com/philipreese/chirpy-go/internal/database"
 )
 
+const (
+	maxDisplayNameLength = 50
+	maxBioLength         = 160
+	maxLocationLength    = 30
+
+	maxPlaceholderHandleAttempts = 3
+)
+
+var handlePattern = regexp.MustCompile(`^[A-Za-z0-9_]{3,30}$`)
+
 type User struct {
-	ID          uuid.UUID `json:"id"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
-	Email       string    `json:"email"`
-	Password    string    `json:"-"`
-	IsChirpyRed bool      `json:"is_chirpy_red"`
+	ID               uuid.UUID `json:"id"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+	Email            string    `json:"email"`
+	Password         string    `json:"-"`
+	IsChirpyRed      bool      `json:"is_chirpy_red"`
+	Handle           string    `json:"handle"`
+	DisplayName      string    `json:"display_name"`
+	Bio              string    `json:"bio"`
+	Location         string    `json:"location"`
+	SensitiveContent string    `json:"sensitive_content"`
+	Role             string    `json:"role"`
 }
 
 type userRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email            string  `json:"email"`
+	Password         string  `json:"password"`
+	Handle           *string `json:"handle"`
+	DisplayName      *string `json:"display_name"`
+	Bio              *string `json:"bio"`
+	Location         *string `json:"location"`
+	SensitiveContent *string `json:"sensitive_content"`
 }
 
 func (cfg *apiConfig) handlerCreateUser(writer http.ResponseWriter, req *http.Request) {
@@ -32,31 +59,45 @@ func (cfg *apiConfig) handlerCreateUser(writer http.ResponseWriter, req *http.Re
 		return
 	}
 
+	if err := validateProfile(userRequest); err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid profile: " + err.Error())
+		return
+	}
+
 	hashedPassword, err := auth.HashPassword(userRequest.Password)
 	if err != nil {
 		respondWithError(writer, http.StatusInternalServerError, "Couldn't hash password: " + err.Error())
 		return
 	}
 
-	dbUser, err := cfg.db.CreateUser(req.Context(), database.CreateUserParams{
+	params := database.CreateUserParams{
 		Email: userRequest.Email,
 		HashedPassword: hashedPassword,
-	})
+		Handle: nullString(userRequest.Handle),
+		DisplayName: valueOrEmpty(userRequest.DisplayName),
+		Bio: valueOrEmpty(userRequest.Bio),
+		Location: valueOrEmpty(userRequest.Location),
+	}
+	dbUser, err := cfg.db.CreateUser(req.Context(), params)
+	// a placeholder handle comes from the new user's ID, so it can clash
+	// with one someone picked; every attempt gets a fresh ID to try
+	for attempt := 1; attempt < maxPlaceholderHandleAttempts && !params.Handle.Valid && isHandleTaken(err); attempt++ {
+		dbUser, err = cfg.db.CreateUser(req.Context(), params)
+	}
 	if err != nil {
+		if isHandleTaken(err) {
+			respondWithError(writer, http.StatusConflict, "Handle is already taken")
+			return
+		}
 		respondWithError(writer, http.StatusInternalServerError, "Couldn't create user: " + err.Error())
 		return
 	}
-	
-	user := User{
-		ID: dbUser.ID,
-		CreatedAt: dbUser.CreatedAt,
-		UpdatedAt: dbUser.UpdatedAt,
-		Email: dbUser.Email,
-		IsChirpyRed: false,
-	}
-	respondWithJSON(writer, http.StatusCreated, user)
+
+	respondWithJSON(writer, http.StatusCreated, databaseUserToUser(dbUser))
 }
 
+// handlerUpdateUser only changes the fields present in the request, so a
+// client can edit a bio without sending the password again.
 func (cfg *apiConfig) handlerUpdateUser(writer http.ResponseWriter, req *http.Request) {
 	tokenString, err := auth.GetBearerToken(req.Header)
 	if err != nil {
@@ -77,27 +118,99 @@ func (cfg *apiConfig) handlerUpdateUser(writer http.ResponseWriter, req *http.Re
 		return
 	}
 
-	hashedPassword, err := auth.HashPassword(userRequest.Password)
-	if err != nil {
-		respondWithError(writer, http.StatusInternalServerError, "Couldn't hash password: " + err.Error())
+	if err := validateProfile(userRequest); err != nil {
+		respondWithError(writer, http.StatusBadRequest, "Invalid profile: " + err.Error())
 		return
 	}
 
+	var hashedPassword sql.NullString
+	if userRequest.Password != "" {
+		hashed, err := auth.HashPassword(userRequest.Password)
+		if err != nil {
+			respondWithError(writer, http.StatusInternalServerError, "Couldn't hash password: " + err.Error())
+			return
+		}
+		hashedPassword = sql.NullString{String: hashed, Valid: true}
+	}
+
 	user, err := cfg.db.UpdateUser(req.Context(), database.UpdateUserParams{
-		ID: userID,
-		Email: userRequest.Email,
+		Email: sql.NullString{String: userRequest.Email, Valid: userRequest.Email != ""},
 		HashedPassword: hashedPassword,
+		Handle: nullString(userRequest.Handle),
+		DisplayName: nullString(userRequest.DisplayName),
+		Bio: nullString(userRequest.Bio),
+		Location: nullString(userRequest.Location),
+		SensitiveContent: nullString(userRequest.SensitiveContent),
+		ID: userID,
 	})
 	if err != nil {
+		if isHandleTaken(err) {
+			respondWithError(writer, http.StatusConflict, "Handle is already taken")
+			return
+		}
 		respondWithError(writer, http.StatusInternalServerError, "Failed to update user: " + err.Error())
 		return
 	}
 
-	respondWithJSON(writer, http.StatusOK, User{
-		ID: user.ID,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
-		Email: user.Email,
-		IsChirpyRed: user.IsChirpyRed,
-	})
-}
\ No newline at end of file
+	respondWithJSON(writer, http.StatusOK, databaseUserToUser(user))
+}
+
+func databaseUserToUser(dbUser database.User) User {
+	return User{
+		ID: dbUser.ID,
+		CreatedAt: dbUser.CreatedAt,
+		UpdatedAt: dbUser.UpdatedAt,
+		Email: dbUser.Email,
+		IsChirpyRed: dbUser.IsChirpyRed,
+		Handle: dbUser.Handle,
+		DisplayName: dbUser.DisplayName,
+		Bio: dbUser.Bio,
+		Location: dbUser.Location,
+		SensitiveContent: dbUser.SensitiveContent,
+		Role: dbUser.Role,
+	}
+}
+
+// validateProfile checks the profile fields present in a request. Handles
+// are compared case-insensitively, but are stored the way they were typed.
+func validateProfile(userRequest userRequest) error {
+	if userRequest.Handle != nil && !handlePattern.MatchString(*userRequest.Handle) {
+		return errors.New("handle must be 3 to 30 letters, digits or underscores")
+	}
+	if userRequest.DisplayName != nil && utf8.RuneCountInString(*userRequest.DisplayName) > maxDisplayNameLength {
+		return fmt.Errorf("display name must be at most %d characters", maxDisplayNameLength)
+	}
+	if userRequest.Bio != nil && utf8.RuneCountInString(*userRequest.Bio) > maxBioLength {
+		return fmt.Errorf("bio must be at most %d characters", maxBioLength)
+	}
+	if userRequest.Location != nil && utf8.RuneCountInString(*userRequest.Location) > maxLocationLength {
+		return fmt.Errorf("location must be at most %d characters", maxLocationLength)
+	}
+	if userRequest.SensitiveContent != nil {
+		switch *userRequest.SensitiveContent {
+		case SensitiveContentExpand, SensitiveContentHide, SensitiveContentWithhold:
+		default:
+			return errors.New("sensitive_content must be expand, hide or withhold")
+		}
+	}
+	return nil
+}
+
+func isHandleTaken(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "users_handle_idx"
+}
+
+func nullString(s *string) sql.NullString {
+	if s == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *s, Valid: true}
+}
+
+func valueOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -41,7 +41,15 @@ func (cfg *apiConfig) handlerWebhook(writer http.ResponseWriter, req *http.Reque
 		return
 	}
 
-	_, err = cfg.db.UpgradeUser(req.Context(), webhookEvent.Data.UserID)
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't start transaction: " + err.Error())
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	user, err := qtx.GetUserByID(req.Context(), webhookEvent.Data.UserID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			respondWithError(writer, http.StatusNotFound, "User not found: " + err.Error())
@@ -51,5 +59,24 @@ func (cfg *apiConfig) handlerWebhook(writer http.ResponseWriter, req *http.Reque
 		return
 	}
 
+	// Polka retries webhooks, sometimes concurrently, so the upgrade only
+	// changes a user who isn't already upgraded, and only the delivery that
+	// changed them gets a notification
+	_, err = qtx.UpgradeUser(req.Context(), user.ID)
+	if err == nil {
+		if err := notify(req.Context(), qtx, user.ID, NotificationChirpyRed, chirpyRedPayload{}); err != nil {
+			respondWithError(writer, http.StatusInternalServerError, "Couldn't create notification: " + err.Error())
+			return
+		}
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		respondWithError(writer, http.StatusNotFound, "Couldn't update user: " + err.Error())
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(writer, http.StatusInternalServerError, "Couldn't update user: " + err.Error())
+		return
+	}
+
 	writer.WriteHeader(http.StatusNoContent)
 }
\ No newline at end of file
@@ -0,0 +1,107 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrInvalidKey = errors.New("invalid blob key")
+
+// BlobStore holds uploaded files. Keys are slash-separated relative paths
+// picked by the caller, such as "3f2a….jpg", and must not contain "..".
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
+
+// LocalStore keeps blobs as files under a directory on local disk and hands
+// out URLs under baseURL, which the server is expected to serve with
+// Handler.
+type LocalStore struct {
+	root    string
+	baseURL string
+}
+
+func NewLocalStore(root, baseURL string) (*LocalStore, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &LocalStore{
+		root: root,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}, nil
+}
+
+// Put writes the blob to a temporary file first and renames it into place,
+// so a half-written upload is never served.
+func (s *LocalStore) Put(ctx context.Context, key string, r io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Delete removes a blob. Deleting one that doesn't exist is not an error.
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalStore) URL(key string) string {
+	return s.baseURL + "/" + key
+}
+
+// Handler serves the stored blobs. Directory listings are refused so the
+// keys of unattached uploads can't be discovered.
+func (s *LocalStore) Handler() http.Handler {
+	files := http.FileServer(http.Dir(s.root))
+	return http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		if strings.HasSuffix(req.URL.Path, "/") {
+			http.NotFound(writer, req)
+			return
+		}
+		files.ServeHTTP(writer, req)
+	})
+}
+
+func (s *LocalStore) path(key string) (string, error) {
+	if !fs.ValidPath(key) || key == "." {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
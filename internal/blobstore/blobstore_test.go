@@ -0,0 +1,56 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalStore(t *testing.T) {
+	root := t.TempDir()
+	store, err := NewLocalStore(root, "/media/")
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+
+	if err := store.Put(context.Background(), "a/b.txt", strings.NewReader("hello")); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(root, "a", "b.txt"))
+	if err != nil || string(data) != "hello" {
+		t.Errorf("expected file contents %q, got %q (err %v)", "hello", data, err)
+	}
+
+	if url := store.URL("a/b.txt"); url != "/media/a/b.txt" {
+		t.Errorf("expected URL %q, got %q", "/media/a/b.txt", url)
+	}
+
+	if err := store.Delete(context.Background(), "a/b.txt"); err != nil {
+		t.Errorf("Delete failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "a", "b.txt")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected file to be gone, got %v", err)
+	}
+
+	if err := store.Delete(context.Background(), "a/b.txt"); err != nil {
+		t.Errorf("expected deleting a missing blob to succeed, got %v", err)
+	}
+}
+
+func TestLocalStoreInvalidKeys(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir(), "/media")
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+
+	for _, key := range []string{"../escape.txt", "/abs.txt", "a/../../b", "", "."} {
+		err := store.Put(context.Background(), key, strings.NewReader("x"))
+		if !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("key %q: expected ErrInvalidKey, got %v", key, err)
+		}
+	}
+}
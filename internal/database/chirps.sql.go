@@ -7,23 +7,126 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const approveHeldChirp = `-- name: ApproveHeldChirp :one
+UPDATE chirps
+SET held_for_review = FALSE
+WHERE id = $1
+    AND held_for_review
+    AND tombstoned_at IS NULL
+    AND deleted_at IS NULL
+RETURNING id, created_at, updated_at, body, user_id, search_vector, parent_id, tombstoned_at, quoted_chirp_id, visibility, content_warning, sensitive, held_for_review, deleted_at, deleted_by, purge_failed_at
+`
+
+func (q *Queries) ApproveHeldChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, approveHeldChirp, id)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.SearchVector,
+		&i.ParentID,
+		&i.TombstonedAt,
+		&i.QuotedChirpID,
+		&i.Visibility,
+		&i.ContentWarning,
+		&i.Sensitive,
+		&i.HeldForReview,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.PurgeFailedAt,
+	)
+	return i, err
+}
+
+const claimExpiredTrashedChirp = `-- name: ClaimExpiredTrashedChirp :one
+SELECT id, created_at, updated_at, body, user_id, search_vector, parent_id, tombstoned_at, quoted_chirp_id, visibility, content_warning, sensitive, held_for_review, deleted_at, deleted_by, purge_failed_at FROM chirps
+WHERE deleted_at <= $1
+    AND deleted_by = user_id
+    AND (purge_failed_at IS NULL OR purge_failed_at <= $2)
+ORDER BY deleted_at ASC, id ASC
+LIMIT 1
+FOR UPDATE SKIP LOCKED
+`
+
+type ClaimExpiredTrashedChirpParams struct {
+	DeletedAt     time.Time
+	PurgeFailedAt time.Time
+}
+
+func (q *Queries) ClaimExpiredTrashedChirp(ctx context.Context, arg ClaimExpiredTrashedChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, claimExpiredTrashedChirp, arg.DeletedAt, arg.PurgeFailedAt)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.SearchVector,
+		&i.ParentID,
+		&i.TombstonedAt,
+		&i.QuotedChirpID,
+		&i.Visibility,
+		&i.ContentWarning,
+		&i.Sensitive,
+		&i.HeldForReview,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.PurgeFailedAt,
+	)
+	return i, err
+}
+
+const countChirpReplies = `-- name: CountChirpReplies :one
+SELECT COUNT(*) FROM chirps
+WHERE parent_id = $1::uuid
+`
+
+func (q *Queries) CountChirpReplies(ctx context.Context, parentID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countChirpReplies, parentID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createChirp = `-- name: CreateChirp :one
-INSERT INTO chirps(id, created_at, updated_at, body, user_id)
-VALUES (gen_random_uuid(), NOW(), NOW(), $1, $2)
-RETURNING id, created_at, updated_at, body, user_id
+INSERT INTO chirps(id, created_at, updated_at, body, user_id, parent_id, quoted_chirp_id, visibility, content_warning, sensitive, held_for_review)
+VALUES (gen_random_uuid(), NOW(), NOW(), $1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, created_at, updated_at, body, user_id, search_vector, parent_id, tombstoned_at, quoted_chirp_id, visibility, content_warning, sensitive, held_for_review, deleted_at, deleted_by, purge_failed_at
 `
 
 type CreateChirpParams struct {
-	Body   string
-	UserID uuid.UUID
+	Body           string
+	UserID         uuid.UUID
+	ParentID       uuid.NullUUID
+	QuotedChirpID  uuid.NullUUID
+	Visibility     string
+	ContentWarning string
+	Sensitive      bool
+	HeldForReview  bool
 }
 
 func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp, error) {
-	row := q.db.QueryRowContext(ctx, createChirp, arg.Body, arg.UserID)
+	row := q.db.QueryRowContext(ctx, createChirp,
+		arg.Body,
+		arg.UserID,
+		arg.ParentID,
+		arg.QuotedChirpID,
+		arg.Visibility,
+		arg.ContentWarning,
+		arg.Sensitive,
+		arg.HeldForReview,
+	)
 	var i Chirp
 	err := row.Scan(
 		&i.ID,
@@ -31,6 +134,17 @@ func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.SearchVector,
+		&i.ParentID,
+		&i.TombstonedAt,
+		&i.QuotedChirpID,
+		&i.Visibility,
+		&i.ContentWarning,
+		&i.Sensitive,
+		&i.HeldForReview,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.PurgeFailedAt,
 	)
 	return i, err
 }
@@ -46,12 +160,50 @@ func (q *Queries) DeleteChirp(ctx context.Context, id uuid.UUID) error {
 }
 
 const getChirpByID = `-- name: GetChirpByID :one
-SELECT id, created_at, updated_at, body, user_id FROM chirps
+SELECT id, created_at, updated_at, body, user_id, search_vector, parent_id, tombstoned_at, quoted_chirp_id, visibility, content_warning, sensitive, held_for_review, deleted_at, deleted_by, purge_failed_at FROM chirps
+WHERE id = $1
+    AND deleted_at IS NULL
+    AND chirp_visible_to(id, $2::uuid, FALSE)
+`
+
+type GetChirpByIDParams struct {
+	ID       uuid.UUID
+	ViewerID uuid.NullUUID
+}
+
+func (q *Queries) GetChirpByID(ctx context.Context, arg GetChirpByIDParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getChirpByID, arg.ID, arg.ViewerID)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.SearchVector,
+		&i.ParentID,
+		&i.TombstonedAt,
+		&i.QuotedChirpID,
+		&i.Visibility,
+		&i.ContentWarning,
+		&i.Sensitive,
+		&i.HeldForReview,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.PurgeFailedAt,
+	)
+	return i, err
+}
+
+const getChirpByIDForUpdate = `-- name: GetChirpByIDForUpdate :one
+SELECT id, created_at, updated_at, body, user_id, search_vector, parent_id, tombstoned_at, quoted_chirp_id, visibility, content_warning, sensitive, held_for_review, deleted_at, deleted_by, purge_failed_at FROM chirps
 WHERE id = $1
+    AND deleted_at IS NULL
+FOR UPDATE
 `
 
-func (q *Queries) GetChirpByID(ctx context.Context, id uuid.UUID) (Chirp, error) {
-	row := q.db.QueryRowContext(ctx, getChirpByID, id)
+func (q *Queries) GetChirpByIDForUpdate(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getChirpByIDForUpdate, id)
 	var i Chirp
 	err := row.Scan(
 		&i.ID,
@@ -59,17 +211,214 @@ func (q *Queries) GetChirpByID(ctx context.Context, id uuid.UUID) (Chirp, error)
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.SearchVector,
+		&i.ParentID,
+		&i.TombstonedAt,
+		&i.QuotedChirpID,
+		&i.Visibility,
+		&i.ContentWarning,
+		&i.Sensitive,
+		&i.HeldForReview,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.PurgeFailedAt,
 	)
 	return i, err
 }
 
+const getChirpIncludingDeleted = `-- name: GetChirpIncludingDeleted :one
+SELECT id, created_at, updated_at, body, user_id, search_vector, parent_id, tombstoned_at, quoted_chirp_id, visibility, content_warning, sensitive, held_for_review, deleted_at, deleted_by, purge_failed_at FROM chirps
+WHERE id = $1
+`
+
+func (q *Queries) GetChirpIncludingDeleted(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getChirpIncludingDeleted, id)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.SearchVector,
+		&i.ParentID,
+		&i.TombstonedAt,
+		&i.QuotedChirpID,
+		&i.Visibility,
+		&i.ContentWarning,
+		&i.Sensitive,
+		&i.HeldForReview,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.PurgeFailedAt,
+	)
+	return i, err
+}
+
+const getChirpThread = `-- name: GetChirpThread :many
+WITH RECURSIVE thread(id, depth) AS (
+    SELECT chirps.id, 0 FROM chirps
+    WHERE chirps.id = $1
+        AND (chirps.deleted_at IS NULL
+            OR EXISTS (SELECT 1 FROM chirps AS replies
+                WHERE replies.parent_id = chirps.id
+                    AND replies.deleted_at IS NULL
+                    AND chirp_visible_to(replies.id, $2::uuid, FALSE)))
+        AND chirp_visible_to(chirps.id, $2::uuid, FALSE)
+    UNION ALL
+    SELECT chirps.id, thread.depth + 1 FROM chirps
+    JOIN thread ON chirps.parent_id = thread.id
+    WHERE thread.depth < $3::int
+        AND (chirps.deleted_at IS NULL
+            OR EXISTS (SELECT 1 FROM chirps AS replies
+                WHERE replies.parent_id = chirps.id
+                    AND replies.deleted_at IS NULL
+                    AND chirp_visible_to(replies.id, $2::uuid, FALSE)))
+        AND chirp_visible_to(chirps.id, $2::uuid, FALSE)
+)
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.search_vector, chirps.parent_id, chirps.tombstoned_at, chirps.quoted_chirp_id, chirps.visibility, chirps.content_warning, chirps.sensitive, chirps.held_for_review, chirps.deleted_at, chirps.deleted_by, chirps.purge_failed_at, thread.depth::int AS depth
+FROM thread
+JOIN chirps ON chirps.id = thread.id
+ORDER BY thread.depth, chirps.created_at, chirps.id
+LIMIT $4
+`
+
+type GetChirpThreadParams struct {
+	RootID   uuid.UUID
+	ViewerID uuid.NullUUID
+	MaxDepth int32
+	Limit    int32
+}
+
+type GetChirpThreadRow struct {
+	Chirp Chirp
+	Depth int32
+}
+
+func (q *Queries) GetChirpThread(ctx context.Context, arg GetChirpThreadParams) ([]GetChirpThreadRow, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpThread,
+		arg.RootID,
+		arg.ViewerID,
+		arg.MaxDepth,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetChirpThreadRow
+	for rows.Next() {
+		var i GetChirpThreadRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.SearchVector,
+			&i.Chirp.ParentID,
+			&i.Chirp.TombstonedAt,
+			&i.Chirp.QuotedChirpID,
+			&i.Chirp.Visibility,
+			&i.Chirp.ContentWarning,
+			&i.Chirp.Sensitive,
+			&i.Chirp.HeldForReview,
+			&i.Chirp.DeletedAt,
+			&i.Chirp.DeletedBy,
+			&i.Chirp.PurgeFailedAt,
+			&i.Depth,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getChirps = `-- name: GetChirps :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps
-ORDER BY created_at ASC
+SELECT id, created_at, updated_at, body, user_id, search_vector, parent_id, tombstoned_at, quoted_chirp_id, visibility, content_warning, sensitive, held_for_review, deleted_at, deleted_by, purge_failed_at FROM chirps
+WHERE tombstoned_at IS NULL
+    AND deleted_at IS NULL
+    AND chirp_visible_to(id, $1::uuid, TRUE)
+    AND ($2::timestamp IS NULL
+        OR (created_at, id) > ($2::timestamp, $3::uuid))
+ORDER BY created_at ASC, id ASC
+LIMIT $4
+`
+
+type GetChirpsParams struct {
+	ViewerID        uuid.NullUUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	Limit           int32
+}
+
+func (q *Queries) GetChirps(ctx context.Context, arg GetChirpsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirps,
+		arg.ViewerID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.ParentID,
+			&i.TombstonedAt,
+			&i.QuotedChirpID,
+			&i.Visibility,
+			&i.ContentWarning,
+			&i.Sensitive,
+			&i.HeldForReview,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.PurgeFailedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpsByIDs = `-- name: GetChirpsByIDs :many
+SELECT id, created_at, updated_at, body, user_id, search_vector, parent_id, tombstoned_at, quoted_chirp_id, visibility, content_warning, sensitive, held_for_review, deleted_at, deleted_by, purge_failed_at FROM chirps
+WHERE id = ANY($1::uuid[])
+    AND tombstoned_at IS NULL
+    AND deleted_at IS NULL
+    AND chirp_visible_to(id, $2::uuid, FALSE)
 `
 
-func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getChirps)
+type GetChirpsByIDsParams struct {
+	Ids      []uuid.UUID
+	ViewerID uuid.NullUUID
+}
+
+func (q *Queries) GetChirpsByIDs(ctx context.Context, arg GetChirpsByIDsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsByIDs, pq.Array(arg.Ids), arg.ViewerID)
 	if err != nil {
 		return nil, err
 	}
@@ -83,6 +432,17 @@ func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.SearchVector,
+			&i.ParentID,
+			&i.TombstonedAt,
+			&i.QuotedChirpID,
+			&i.Visibility,
+			&i.ContentWarning,
+			&i.Sensitive,
+			&i.HeldForReview,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.PurgeFailedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -98,13 +458,676 @@ func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
 }
 
 const getChirpsByUserID = `-- name: GetChirpsByUserID :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.search_vector, chirps.parent_id, chirps.tombstoned_at, chirps.quoted_chirp_id, chirps.visibility, chirps.content_warning, chirps.sensitive, chirps.held_for_review, chirps.deleted_at, chirps.deleted_by, chirps.purge_failed_at, feed.active_at, feed.rechirped_by
+FROM (
+    (SELECT chirps.id AS chirp_id, chirps.created_at AS active_at, NULL::uuid AS rechirped_by
+    FROM chirps
+    WHERE chirps.user_id = $1
+        AND NOT EXISTS (SELECT 1 FROM rechirps
+            WHERE rechirps.chirp_id = chirps.id
+                AND rechirps.user_id = $1
+                AND user_visible_to(rechirps.user_id, $2::uuid, TRUE))
+        AND chirps.tombstoned_at IS NULL
+        AND chirps.deleted_at IS NULL
+        AND chirp_visible_to(chirps.id, $2::uuid, TRUE)
+        AND ($3::timestamp IS NULL
+            OR (chirps.created_at, chirps.id) > ($3::timestamp, $4::uuid))
+    ORDER BY chirps.created_at ASC, chirps.id ASC
+    LIMIT $5)
+    UNION ALL
+    (SELECT rechirps.chirp_id, rechirps.created_at, rechirps.user_id
+    FROM rechirps
+    JOIN chirps ON chirps.id = rechirps.chirp_id
+    WHERE rechirps.user_id = $1
+        AND user_visible_to(rechirps.user_id, $2::uuid, TRUE)
+        AND NOT EXISTS (SELECT 1 FROM rechirps AS later
+            WHERE later.chirp_id = rechirps.chirp_id
+                AND later.user_id = $1
+                AND user_visible_to(later.user_id, $2::uuid, TRUE)
+                AND (later.created_at, later.user_id) > (rechirps.created_at, rechirps.user_id))
+        AND chirps.tombstoned_at IS NULL
+        AND chirps.deleted_at IS NULL
+        AND chirp_visible_to(chirps.id, $2::uuid, TRUE)
+        AND ($3::timestamp IS NULL
+            OR (rechirps.created_at, rechirps.chirp_id) > ($3::timestamp, $4::uuid))
+    ORDER BY rechirps.created_at ASC, rechirps.chirp_id ASC
+    LIMIT $5)
+) AS feed
+JOIN chirps ON chirps.id = feed.chirp_id
+ORDER BY feed.active_at ASC, chirps.id ASC
+LIMIT $5
+`
+
+type GetChirpsByUserIDParams struct {
+	UserID          uuid.UUID
+	ViewerID        uuid.NullUUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	Limit           int32
+}
+
+type GetChirpsByUserIDRow struct {
+	Chirp       Chirp
+	ActiveAt    time.Time
+	RechirpedBy uuid.NullUUID
+}
+
+func (q *Queries) GetChirpsByUserID(ctx context.Context, arg GetChirpsByUserIDParams) ([]GetChirpsByUserIDRow, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsByUserID,
+		arg.UserID,
+		arg.ViewerID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetChirpsByUserIDRow
+	for rows.Next() {
+		var i GetChirpsByUserIDRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.SearchVector,
+			&i.Chirp.ParentID,
+			&i.Chirp.TombstonedAt,
+			&i.Chirp.QuotedChirpID,
+			&i.Chirp.Visibility,
+			&i.Chirp.ContentWarning,
+			&i.Chirp.Sensitive,
+			&i.Chirp.HeldForReview,
+			&i.Chirp.DeletedAt,
+			&i.Chirp.DeletedBy,
+			&i.Chirp.PurgeFailedAt,
+			&i.ActiveAt,
+			&i.RechirpedBy,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpsByUserIDDesc = `-- name: GetChirpsByUserIDDesc :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.search_vector, chirps.parent_id, chirps.tombstoned_at, chirps.quoted_chirp_id, chirps.visibility, chirps.content_warning, chirps.sensitive, chirps.held_for_review, chirps.deleted_at, chirps.deleted_by, chirps.purge_failed_at, feed.active_at, feed.rechirped_by
+FROM (
+    (SELECT chirps.id AS chirp_id, chirps.created_at AS active_at, NULL::uuid AS rechirped_by
+    FROM chirps
+    WHERE chirps.user_id = $1
+        AND NOT EXISTS (SELECT 1 FROM rechirps
+            WHERE rechirps.chirp_id = chirps.id
+                AND rechirps.user_id = $1
+                AND user_visible_to(rechirps.user_id, $2::uuid, TRUE))
+        AND chirps.tombstoned_at IS NULL
+        AND chirps.deleted_at IS NULL
+        AND chirp_visible_to(chirps.id, $2::uuid, TRUE)
+        AND ($3::timestamp IS NULL
+            OR (chirps.created_at, chirps.id) < ($3::timestamp, $4::uuid))
+    ORDER BY chirps.created_at DESC, chirps.id DESC
+    LIMIT $5)
+    UNION ALL
+    (SELECT rechirps.chirp_id, rechirps.created_at, rechirps.user_id
+    FROM rechirps
+    JOIN chirps ON chirps.id = rechirps.chirp_id
+    WHERE rechirps.user_id = $1
+        AND user_visible_to(rechirps.user_id, $2::uuid, TRUE)
+        AND NOT EXISTS (SELECT 1 FROM rechirps AS later
+            WHERE later.chirp_id = rechirps.chirp_id
+                AND later.user_id = $1
+                AND user_visible_to(later.user_id, $2::uuid, TRUE)
+                AND (later.created_at, later.user_id) > (rechirps.created_at, rechirps.user_id))
+        AND chirps.tombstoned_at IS NULL
+        AND chirps.deleted_at IS NULL
+        AND chirp_visible_to(chirps.id, $2::uuid, TRUE)
+        AND ($3::timestamp IS NULL
+            OR (rechirps.created_at, rechirps.chirp_id) < ($3::timestamp, $4::uuid))
+    ORDER BY rechirps.created_at DESC, rechirps.chirp_id DESC
+    LIMIT $5)
+) AS feed
+JOIN chirps ON chirps.id = feed.chirp_id
+ORDER BY feed.active_at DESC, chirps.id DESC
+LIMIT $5
+`
+
+type GetChirpsByUserIDDescParams struct {
+	UserID          uuid.UUID
+	ViewerID        uuid.NullUUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	Limit           int32
+}
+
+type GetChirpsByUserIDDescRow struct {
+	Chirp       Chirp
+	ActiveAt    time.Time
+	RechirpedBy uuid.NullUUID
+}
+
+func (q *Queries) GetChirpsByUserIDDesc(ctx context.Context, arg GetChirpsByUserIDDescParams) ([]GetChirpsByUserIDDescRow, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsByUserIDDesc,
+		arg.UserID,
+		arg.ViewerID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetChirpsByUserIDDescRow
+	for rows.Next() {
+		var i GetChirpsByUserIDDescRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.SearchVector,
+			&i.Chirp.ParentID,
+			&i.Chirp.TombstonedAt,
+			&i.Chirp.QuotedChirpID,
+			&i.Chirp.Visibility,
+			&i.Chirp.ContentWarning,
+			&i.Chirp.Sensitive,
+			&i.Chirp.HeldForReview,
+			&i.Chirp.DeletedAt,
+			&i.Chirp.DeletedBy,
+			&i.Chirp.PurgeFailedAt,
+			&i.ActiveAt,
+			&i.RechirpedBy,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpsDesc = `-- name: GetChirpsDesc :many
+SELECT id, created_at, updated_at, body, user_id, search_vector, parent_id, tombstoned_at, quoted_chirp_id, visibility, content_warning, sensitive, held_for_review, deleted_at, deleted_by, purge_failed_at FROM chirps
+WHERE tombstoned_at IS NULL
+    AND deleted_at IS NULL
+    AND chirp_visible_to(id, $1::uuid, TRUE)
+    AND ($2::timestamp IS NULL
+        OR (created_at, id) < ($2::timestamp, $3::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT $4
+`
+
+type GetChirpsDescParams struct {
+	ViewerID        uuid.NullUUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	Limit           int32
+}
+
+func (q *Queries) GetChirpsDesc(ctx context.Context, arg GetChirpsDescParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsDesc,
+		arg.ViewerID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.ParentID,
+			&i.TombstonedAt,
+			&i.QuotedChirpID,
+			&i.Visibility,
+			&i.ContentWarning,
+			&i.Sensitive,
+			&i.HeldForReview,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.PurgeFailedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDeletedChirps = `-- name: GetDeletedChirps :many
+SELECT id, created_at, updated_at, body, user_id, search_vector, parent_id, tombstoned_at, quoted_chirp_id, visibility, content_warning, sensitive, held_for_review, deleted_at, deleted_by, purge_failed_at FROM chirps
+WHERE deleted_at IS NOT NULL
+    AND ($1::uuid IS NULL OR user_id = $1::uuid)
+    AND ($2::timestamp IS NULL
+        OR (deleted_at, id) < ($2::timestamp, $3::uuid))
+ORDER BY deleted_at DESC, id DESC
+LIMIT $4
+`
+
+type GetDeletedChirpsParams struct {
+	UserID          uuid.NullUUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	Limit           int32
+}
+
+func (q *Queries) GetDeletedChirps(ctx context.Context, arg GetDeletedChirpsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getDeletedChirps,
+		arg.UserID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.ParentID,
+			&i.TombstonedAt,
+			&i.QuotedChirpID,
+			&i.Visibility,
+			&i.ContentWarning,
+			&i.Sensitive,
+			&i.HeldForReview,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.PurgeFailedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getHeldChirps = `-- name: GetHeldChirps :many
+SELECT id, created_at, updated_at, body, user_id, search_vector, parent_id, tombstoned_at, quoted_chirp_id, visibility, content_warning, sensitive, held_for_review, deleted_at, deleted_by, purge_failed_at FROM chirps
+WHERE held_for_review
+    AND tombstoned_at IS NULL
+    AND deleted_at IS NULL
+    AND ($1::timestamp IS NULL
+        OR (created_at, id) > ($1::timestamp, $2::uuid))
+ORDER BY created_at ASC, id ASC
+LIMIT $3
+`
+
+type GetHeldChirpsParams struct {
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	Limit           int32
+}
+
+func (q *Queries) GetHeldChirps(ctx context.Context, arg GetHeldChirpsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getHeldChirps, arg.CursorCreatedAt, arg.CursorID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.ParentID,
+			&i.TombstonedAt,
+			&i.QuotedChirpID,
+			&i.Visibility,
+			&i.ContentWarning,
+			&i.Sensitive,
+			&i.HeldForReview,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.PurgeFailedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getReplyCounts = `-- name: GetReplyCounts :many
+SELECT parent_id::uuid AS chirp_id, COUNT(*) AS reply_count
+FROM chirps
+WHERE parent_id = ANY($1::uuid[])
+    AND tombstoned_at IS NULL
+    AND deleted_at IS NULL
+    AND chirp_visible_to(id, $2::uuid, FALSE)
+GROUP BY parent_id
+`
+
+type GetReplyCountsParams struct {
+	ChirpIds []uuid.UUID
+	ViewerID uuid.NullUUID
+}
+
+type GetReplyCountsRow struct {
+	ChirpID    uuid.UUID
+	ReplyCount int64
+}
+
+func (q *Queries) GetReplyCounts(ctx context.Context, arg GetReplyCountsParams) ([]GetReplyCountsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getReplyCounts, pq.Array(arg.ChirpIds), arg.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetReplyCountsRow
+	for rows.Next() {
+		var i GetReplyCountsRow
+		if err := rows.Scan(
+			&i.ChirpID,
+			&i.ReplyCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTimeline = `-- name: GetTimeline :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.search_vector, chirps.parent_id, chirps.tombstoned_at, chirps.quoted_chirp_id, chirps.visibility, chirps.content_warning, chirps.sensitive, chirps.held_for_review, chirps.deleted_at, chirps.deleted_by, chirps.purge_failed_at, feed.active_at, feed.rechirped_by
+FROM (
+    (SELECT chirps.id AS chirp_id, chirps.created_at AS active_at, NULL::uuid AS rechirped_by
+    FROM chirps
+    WHERE (chirps.user_id = $1
+            OR chirps.user_id IN (SELECT followee_id FROM follows WHERE follower_id = $1))
+        AND NOT EXISTS (SELECT 1 FROM rechirps
+            WHERE rechirps.chirp_id = chirps.id
+                AND (rechirps.user_id = $1
+                    OR rechirps.user_id IN (SELECT followee_id FROM follows WHERE follower_id = $1))
+                AND user_visible_to(rechirps.user_id, $1, TRUE))
+        AND chirps.tombstoned_at IS NULL
+        AND chirps.deleted_at IS NULL
+        AND chirp_visible_to(chirps.id, $1, TRUE)
+        AND ($2::timestamp IS NULL
+            OR (chirps.created_at, chirps.id) < ($2::timestamp, $3::uuid))
+    ORDER BY chirps.created_at DESC, chirps.id DESC
+    LIMIT $4)
+    UNION ALL
+    (SELECT rechirps.chirp_id, rechirps.created_at, rechirps.user_id
+    FROM rechirps
+    JOIN chirps ON chirps.id = rechirps.chirp_id
+    WHERE (rechirps.user_id = $1
+            OR rechirps.user_id IN (SELECT followee_id FROM follows WHERE follower_id = $1))
+        AND user_visible_to(rechirps.user_id, $1, TRUE)
+        AND NOT EXISTS (SELECT 1 FROM rechirps AS later
+            WHERE later.chirp_id = rechirps.chirp_id
+                AND (later.user_id = $1
+                    OR later.user_id IN (SELECT followee_id FROM follows WHERE follower_id = $1))
+                AND user_visible_to(later.user_id, $1, TRUE)
+                AND (later.created_at, later.user_id) > (rechirps.created_at, rechirps.user_id))
+        AND chirps.tombstoned_at IS NULL
+        AND chirps.deleted_at IS NULL
+        AND chirp_visible_to(chirps.id, $1, TRUE)
+        AND ($2::timestamp IS NULL
+            OR (rechirps.created_at, rechirps.chirp_id) < ($2::timestamp, $3::uuid))
+    ORDER BY rechirps.created_at DESC, rechirps.chirp_id DESC
+    LIMIT $4)
+) AS feed
+JOIN chirps ON chirps.id = feed.chirp_id
+ORDER BY feed.active_at DESC, chirps.id DESC
+LIMIT $4
+`
+
+type GetTimelineParams struct {
+	UserID          uuid.UUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	Limit           int32
+}
+
+type GetTimelineRow struct {
+	Chirp       Chirp
+	ActiveAt    time.Time
+	RechirpedBy uuid.NullUUID
+}
+
+func (q *Queries) GetTimeline(ctx context.Context, arg GetTimelineParams) ([]GetTimelineRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTimeline,
+		arg.UserID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTimelineRow
+	for rows.Next() {
+		var i GetTimelineRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.SearchVector,
+			&i.Chirp.ParentID,
+			&i.Chirp.TombstonedAt,
+			&i.Chirp.QuotedChirpID,
+			&i.Chirp.Visibility,
+			&i.Chirp.ContentWarning,
+			&i.Chirp.Sensitive,
+			&i.Chirp.HeldForReview,
+			&i.Chirp.DeletedAt,
+			&i.Chirp.DeletedBy,
+			&i.Chirp.PurgeFailedAt,
+			&i.ActiveAt,
+			&i.RechirpedBy,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTimelineNewer = `-- name: GetTimelineNewer :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.search_vector, chirps.parent_id, chirps.tombstoned_at, chirps.quoted_chirp_id, chirps.visibility, chirps.content_warning, chirps.sensitive, chirps.held_for_review, chirps.deleted_at, chirps.deleted_by, chirps.purge_failed_at, feed.active_at, feed.rechirped_by
+FROM (
+    (SELECT chirps.id AS chirp_id, chirps.created_at AS active_at, NULL::uuid AS rechirped_by
+    FROM chirps
+    WHERE (chirps.user_id = $1
+            OR chirps.user_id IN (SELECT followee_id FROM follows WHERE follower_id = $1))
+        AND NOT EXISTS (SELECT 1 FROM rechirps
+            WHERE rechirps.chirp_id = chirps.id
+                AND (rechirps.user_id = $1
+                    OR rechirps.user_id IN (SELECT followee_id FROM follows WHERE follower_id = $1))
+                AND user_visible_to(rechirps.user_id, $1, TRUE))
+        AND chirps.tombstoned_at IS NULL
+        AND chirps.deleted_at IS NULL
+        AND chirp_visible_to(chirps.id, $1, TRUE)
+        AND ($2::timestamp IS NULL
+            OR (chirps.created_at, chirps.id) > ($2::timestamp, $3::uuid))
+    ORDER BY chirps.created_at ASC, chirps.id ASC
+    LIMIT $4)
+    UNION ALL
+    (SELECT rechirps.chirp_id, rechirps.created_at, rechirps.user_id
+    FROM rechirps
+    JOIN chirps ON chirps.id = rechirps.chirp_id
+    WHERE (rechirps.user_id = $1
+            OR rechirps.user_id IN (SELECT followee_id FROM follows WHERE follower_id = $1))
+        AND user_visible_to(rechirps.user_id, $1, TRUE)
+        AND NOT EXISTS (SELECT 1 FROM rechirps AS later
+            WHERE later.chirp_id = rechirps.chirp_id
+                AND (later.user_id = $1
+                    OR later.user_id IN (SELECT followee_id FROM follows WHERE follower_id = $1))
+                AND user_visible_to(later.user_id, $1, TRUE)
+                AND (later.created_at, later.user_id) > (rechirps.created_at, rechirps.user_id))
+        AND chirps.tombstoned_at IS NULL
+        AND chirps.deleted_at IS NULL
+        AND chirp_visible_to(chirps.id, $1, TRUE)
+        AND ($2::timestamp IS NULL
+            OR (rechirps.created_at, rechirps.chirp_id) > ($2::timestamp, $3::uuid))
+    ORDER BY rechirps.created_at ASC, rechirps.chirp_id ASC
+    LIMIT $4)
+) AS feed
+JOIN chirps ON chirps.id = feed.chirp_id
+ORDER BY feed.active_at ASC, chirps.id ASC
+LIMIT $4
+`
+
+type GetTimelineNewerParams struct {
+	UserID          uuid.UUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	Limit           int32
+}
+
+type GetTimelineNewerRow struct {
+	Chirp       Chirp
+	ActiveAt    time.Time
+	RechirpedBy uuid.NullUUID
+}
+
+func (q *Queries) GetTimelineNewer(ctx context.Context, arg GetTimelineNewerParams) ([]GetTimelineNewerRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTimelineNewer,
+		arg.UserID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTimelineNewerRow
+	for rows.Next() {
+		var i GetTimelineNewerRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.SearchVector,
+			&i.Chirp.ParentID,
+			&i.Chirp.TombstonedAt,
+			&i.Chirp.QuotedChirpID,
+			&i.Chirp.Visibility,
+			&i.Chirp.ContentWarning,
+			&i.Chirp.Sensitive,
+			&i.Chirp.HeldForReview,
+			&i.Chirp.DeletedAt,
+			&i.Chirp.DeletedBy,
+			&i.Chirp.PurgeFailedAt,
+			&i.ActiveAt,
+			&i.RechirpedBy,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTrashedChirps = `-- name: GetTrashedChirps :many
+SELECT id, created_at, updated_at, body, user_id, search_vector, parent_id, tombstoned_at, quoted_chirp_id, visibility, content_warning, sensitive, held_for_review, deleted_at, deleted_by, purge_failed_at FROM chirps
 WHERE user_id = $1
-ORDER BY created_at ASC
+    AND deleted_by = $1
+    AND deleted_at > $2
+    AND ($3::timestamp IS NULL
+        OR (deleted_at, id) < ($3::timestamp, $4::uuid))
+ORDER BY deleted_at DESC, id DESC
+LIMIT $5
 `
 
-func (q *Queries) GetChirpsByUserID(ctx context.Context, userID uuid.UUID) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getChirpsByUserID, userID)
+type GetTrashedChirpsParams struct {
+	UserID          uuid.UUID
+	DeletedAfter    time.Time
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	Limit           int32
+}
+
+func (q *Queries) GetTrashedChirps(ctx context.Context, arg GetTrashedChirpsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getTrashedChirps,
+		arg.UserID,
+		arg.DeletedAfter,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -118,6 +1141,150 @@ func (q *Queries) GetChirpsByUserID(ctx context.Context, userID uuid.UUID) ([]Ch
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.SearchVector,
+			&i.ParentID,
+			&i.TombstonedAt,
+			&i.QuotedChirpID,
+			&i.Visibility,
+			&i.ContentWarning,
+			&i.Sensitive,
+			&i.HeldForReview,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.PurgeFailedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const recordChirpPurgeFailure = `-- name: RecordChirpPurgeFailure :exec
+UPDATE chirps
+SET purge_failed_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) RecordChirpPurgeFailure(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, recordChirpPurgeFailure, id)
+	return err
+}
+
+const restoreChirp = `-- name: RestoreChirp :one
+UPDATE chirps
+SET deleted_at = NULL,
+    deleted_by = NULL
+WHERE id = $1
+    AND user_id = $2
+    AND deleted_by = $2
+    AND deleted_at > $3
+RETURNING id, created_at, updated_at, body, user_id, search_vector, parent_id, tombstoned_at, quoted_chirp_id, visibility, content_warning, sensitive, held_for_review, deleted_at, deleted_by, purge_failed_at
+`
+
+type RestoreChirpParams struct {
+	ID           uuid.UUID
+	UserID       uuid.UUID
+	DeletedAfter time.Time
+}
+
+func (q *Queries) RestoreChirp(ctx context.Context, arg RestoreChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, restoreChirp, arg.ID, arg.UserID, arg.DeletedAfter)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.SearchVector,
+		&i.ParentID,
+		&i.TombstonedAt,
+		&i.QuotedChirpID,
+		&i.Visibility,
+		&i.ContentWarning,
+		&i.Sensitive,
+		&i.HeldForReview,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.PurgeFailedAt,
+	)
+	return i, err
+}
+
+const searchChirps = `-- name: SearchChirps :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.search_vector, chirps.parent_id, chirps.tombstoned_at, chirps.quoted_chirp_id, chirps.visibility, chirps.content_warning, chirps.sensitive, chirps.held_for_review, chirps.deleted_at, chirps.deleted_by, chirps.purge_failed_at,
+    ts_rank(search_vector, to_tsquery('english', $1))::real AS rank,
+    ts_headline('english', body, to_tsquery('english', $1),
+        'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, FragmentDelimiter=" … "')::text AS snippet
+FROM chirps
+WHERE search_vector @@ to_tsquery('english', $1)
+    AND tombstoned_at IS NULL
+    AND deleted_at IS NULL
+    AND chirp_visible_to(id, $2::uuid, TRUE)
+    AND ($3::real IS NULL
+        OR (ts_rank(search_vector, to_tsquery('english', $1))::real, created_at, id)
+            < ($3::real, $4::timestamp, $5::uuid))
+ORDER BY rank DESC, created_at DESC, id DESC
+LIMIT $6
+`
+
+type SearchChirpsParams struct {
+	Query           string
+	ViewerID        uuid.NullUUID
+	CursorRank      sql.NullFloat64
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	Limit           int32
+}
+
+type SearchChirpsRow struct {
+	Chirp   Chirp
+	Rank    float32
+	Snippet string
+}
+
+func (q *Queries) SearchChirps(ctx context.Context, arg SearchChirpsParams) ([]SearchChirpsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchChirps,
+		arg.Query,
+		arg.ViewerID,
+		arg.CursorRank,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchChirpsRow
+	for rows.Next() {
+		var i SearchChirpsRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.SearchVector,
+			&i.Chirp.ParentID,
+			&i.Chirp.TombstonedAt,
+			&i.Chirp.QuotedChirpID,
+			&i.Chirp.Visibility,
+			&i.Chirp.ContentWarning,
+			&i.Chirp.Sensitive,
+			&i.Chirp.HeldForReview,
+			&i.Chirp.DeletedAt,
+			&i.Chirp.DeletedBy,
+			&i.Chirp.PurgeFailedAt,
+			&i.Rank,
+			&i.Snippet,
 		); err != nil {
 			return nil, err
 		}
@@ -131,3 +1298,123 @@ func (q *Queries) GetChirpsByUserID(ctx context.Context, userID uuid.UUID) ([]Ch
 	}
 	return items, nil
 }
+
+const setChirpSensitive = `-- name: SetChirpSensitive :one
+UPDATE chirps
+SET sensitive = $1,
+    updated_at = NOW()
+WHERE id = $2
+    AND tombstoned_at IS NULL
+    AND deleted_at IS NULL
+RETURNING id, created_at, updated_at, body, user_id, search_vector, parent_id, tombstoned_at, quoted_chirp_id, visibility, content_warning, sensitive, held_for_review, deleted_at, deleted_by, purge_failed_at
+`
+
+type SetChirpSensitiveParams struct {
+	Sensitive bool
+	ID        uuid.UUID
+}
+
+func (q *Queries) SetChirpSensitive(ctx context.Context, arg SetChirpSensitiveParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, setChirpSensitive, arg.Sensitive, arg.ID)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.SearchVector,
+		&i.ParentID,
+		&i.TombstonedAt,
+		&i.QuotedChirpID,
+		&i.Visibility,
+		&i.ContentWarning,
+		&i.Sensitive,
+		&i.HeldForReview,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.PurgeFailedAt,
+	)
+	return i, err
+}
+
+const tombstoneChirp = `-- name: TombstoneChirp :exec
+UPDATE chirps
+SET body = '',
+    content_warning = '',
+    tombstoned_at = NOW(),
+    deleted_at = NULL,
+    deleted_by = NULL,
+    updated_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) TombstoneChirp(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, tombstoneChirp, id)
+	return err
+}
+
+const trashChirp = `-- name: TrashChirp :exec
+UPDATE chirps
+SET deleted_at = NOW(),
+    deleted_by = $1
+WHERE id = $2
+    AND deleted_at IS NULL
+`
+
+type TrashChirpParams struct {
+	DeletedBy uuid.NullUUID
+	ID        uuid.UUID
+}
+
+func (q *Queries) TrashChirp(ctx context.Context, arg TrashChirpParams) error {
+	_, err := q.db.ExecContext(ctx, trashChirp, arg.DeletedBy, arg.ID)
+	return err
+}
+
+const updateChirpBody = `-- name: UpdateChirpBody :one
+UPDATE chirps
+SET body = $1,
+    held_for_review = held_for_review OR $2,
+    updated_at = NOW()
+WHERE id = $3
+    AND deleted_at IS NULL
+    AND created_at > NOW() - make_interval(secs => $4::float8)
+RETURNING id, created_at, updated_at, body, user_id, search_vector, parent_id, tombstoned_at, quoted_chirp_id, visibility, content_warning, sensitive, held_for_review, deleted_at, deleted_by, purge_failed_at
+`
+
+type UpdateChirpBodyParams struct {
+	Body              string
+	HeldForReview     bool
+	ID                uuid.UUID
+	EditWindowSeconds float64
+}
+
+func (q *Queries) UpdateChirpBody(ctx context.Context, arg UpdateChirpBodyParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, updateChirpBody,
+		arg.Body,
+		arg.HeldForReview,
+		arg.ID,
+		arg.EditWindowSeconds,
+	)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.SearchVector,
+		&i.ParentID,
+		&i.TombstonedAt,
+		&i.QuotedChirpID,
+		&i.Visibility,
+		&i.ContentWarning,
+		&i.Sensitive,
+		&i.HeldForReview,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.PurgeFailedAt,
+	)
+	return i, err
+}
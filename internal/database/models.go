@@ -6,17 +6,188 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type Block struct {
+	BlockerID uuid.UUID
+	BlockedID uuid.UUID
+	CreatedAt time.Time
+}
+
 type Chirp struct {
+	ID             uuid.UUID
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	Body           string
+	UserID         uuid.UUID
+	SearchVector   interface{}
+	ParentID       uuid.NullUUID
+	TombstonedAt   sql.NullTime
+	QuotedChirpID  uuid.NullUUID
+	Visibility     string
+	ContentWarning string
+	Sensitive      bool
+	HeldForReview  bool
+	DeletedAt      sql.NullTime
+	DeletedBy      uuid.NullUUID
+	PurgeFailedAt  sql.NullTime
+}
+
+type ChirpFingerprint struct {
+	ChirpID     uuid.UUID
+	UserID      uuid.UUID
+	CreatedAt   time.Time
+	ContentHash string
+	Shingles    []int64
+	Links       []string
+}
+
+type ChirpLike struct {
+	UserID    uuid.UUID
+	ChirpID   uuid.UUID
+	CreatedAt time.Time
+}
+
+type ChirpRevision struct {
+	ID         uuid.UUID
+	ChirpID    uuid.UUID
+	Body       string
+	CreatedAt  time.Time
+	ReplacedAt time.Time
+}
+
+type ChirpTag struct {
+	ChirpID   uuid.UUID
+	TagID     uuid.UUID
+	CreatedAt time.Time
+}
+
+type Conversation struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type ConversationParticipant struct {
+	ConversationID uuid.UUID
+	UserID         uuid.UUID
+	JoinedAt       time.Time
+	LastReadAt     sql.NullTime
+	DeletedAt      sql.NullTime
+}
+
+type Draft struct {
+	ID             uuid.UUID
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	UserID         uuid.UUID
+	Body           string
+	ParentID       uuid.NullUUID
+	QuotedChirpID  uuid.NullUUID
+	Version        int32
+	Visibility     string
+	ContentWarning string
+	Sensitive      bool
+}
+
+type Follow struct {
+	FollowerID uuid.UUID
+	FolloweeID uuid.UUID
+	CreatedAt  time.Time
+}
+
+type LoginDevice struct {
+	UserID      uuid.UUID
+	DeviceHash  string
+	FirstSeenAt time.Time
+	LastSeenAt  time.Time
+}
+
+type MediaAttachment struct {
+	ID               uuid.UUID
+	UserID           uuid.UUID
+	ChirpID          uuid.NullUUID
+	Position         int32
+	ContentType      string
+	StorageKey       string
+	ThumbnailKey     string
+	Width            int32
+	Height           int32
+	ThumbnailWidth   int32
+	ThumbnailHeight  int32
+	SizeBytes        int32
+	AltText          string
+	CreatedAt        time.Time
+	ScheduledChirpID uuid.NullUUID
+}
+
+type Message struct {
+	ID             uuid.UUID
+	ConversationID uuid.UUID
+	SenderID       uuid.UUID
+	Body           string
+	CreatedAt      time.Time
+}
+
+type ModerationRule struct {
 	ID        uuid.UUID
 	CreatedAt time.Time
 	UpdatedAt time.Time
-	Body      string
+	Kind      string
+	Pattern   string
+	Action    string
+}
+
+type Mute struct {
+	MuterID   uuid.UUID
+	MutedID   uuid.UUID
+	CreatedAt time.Time
+}
+
+type Notification struct {
+	ID        uuid.UUID
 	UserID    uuid.UUID
+	Type      string
+	Payload   json.RawMessage
+	CreatedAt time.Time
+	ReadAt    sql.NullTime
+}
+
+type Poll struct {
+	ChirpID   uuid.UUID
+	ClosesAt  time.Time
+	CreatedAt time.Time
+}
+
+type PollOption struct {
+	ID       uuid.UUID
+	ChirpID  uuid.UUID
+	Position int32
+	Text     string
+}
+
+type PollVote struct {
+	ChirpID   uuid.UUID
+	UserID    uuid.UUID
+	OptionID  uuid.UUID
+	CreatedAt time.Time
+}
+
+type RateLimitBucket struct {
+	Key       string
+	Tokens    float64
+	UpdatedAt time.Time
+	FullAt    time.Time
+}
+
+type Rechirp struct {
+	UserID    uuid.UUID
+	ChirpID   uuid.UUID
+	CreatedAt time.Time
 }
 
 type RefreshToken struct {
@@ -28,11 +199,75 @@ type RefreshToken struct {
 	RevokedAt sql.NullTime
 }
 
-type User struct {
+type Report struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	CaseID     uuid.UUID
+	ReporterID uuid.UUID
+	Reason     string
+	Comment    string
+}
+
+type ReportCase struct {
+	ID             uuid.UUID
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	ChirpID        uuid.NullUUID
+	ReportedUserID uuid.UUID
+	Status         string
+	ClaimedBy      uuid.NullUUID
+	Resolution     string
+	ResolutionNote string
+	ResolvedAt     sql.NullTime
+}
+
+type ReportCaseEvent struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	CaseID    uuid.UUID
+	ActorID   uuid.NullUUID
+	Action    string
+	Note      string
+}
+
+type ScheduledChirp struct {
 	ID             uuid.UUID
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
-	Email          string
-	HashedPassword string
-	IsChirpyRed    bool
+	PublishAt      time.Time
+	Body           string
+	UserID         uuid.UUID
+	ParentID       uuid.NullUUID
+	QuotedChirpID  uuid.NullUUID
+	Visibility     string
+	ContentWarning string
+	Sensitive      bool
+	FailedAttempts int32
+	LastFailedAt   sql.NullTime
+}
+
+type Tag struct {
+	ID        uuid.UUID
+	Name      string
+	CreatedAt time.Time
+}
+
+type User struct {
+	ID                    uuid.UUID
+	CreatedAt             time.Time
+	UpdatedAt             time.Time
+	Email                 string
+	HashedPassword        string
+	IsChirpyRed           bool
+	Handle                string
+	DisplayName           string
+	Bio                   string
+	Location              string
+	SensitiveContent      string
+	Role                  string
+	SuspendedAt           sql.NullTime
+	SuspendedUntil        sql.NullTime
+	SuspensionReason      string
+	SuspensionHidesChirps bool
+	ShadowBanned          bool
 }
@@ -7,23 +7,54 @@ package database
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const countUsersByIDs = `-- name: CountUsersByIDs :one
+SELECT COUNT(*) FROM users
+WHERE id = ANY($1::uuid[])
+`
+
+func (q *Queries) CountUsersByIDs(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUsersByIDs, pq.Array(ids))
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createUser = `-- name: CreateUser :one
-INSERT INTO users(id, created_at, updated_at, email, hashed_password)
-VALUES (gen_random_uuid(), NOW(), NOW(), $1, $2)
-RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red
+WITH new_user AS (
+    SELECT gen_random_uuid() AS id
+)
+INSERT INTO users(id, created_at, updated_at, email, hashed_password, handle, display_name, bio, location)
+SELECT id, NOW(), NOW(), $1, $2,
+    COALESCE($3, 'user_' || left(replace(id::text, '-', ''), 12)),
+    $4, $5, $6
+FROM new_user
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, display_name, bio, location, sensitive_content, role, suspended_at, suspended_until, suspension_reason, suspension_hides_chirps, shadow_banned
 `
 
 type CreateUserParams struct {
 	Email          string
 	HashedPassword string
+	Handle         sql.NullString
+	DisplayName    string
+	Bio            string
+	Location       string
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, createUser, arg.Email, arg.HashedPassword)
+	row := q.db.QueryRowContext(ctx, createUser,
+		arg.Email,
+		arg.HashedPassword,
+		arg.Handle,
+		arg.DisplayName,
+		arg.Bio,
+		arg.Location,
+	)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -32,12 +63,23 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Location,
+		&i.SensitiveContent,
+		&i.Role,
+		&i.SuspendedAt,
+		&i.SuspendedUntil,
+		&i.SuspensionReason,
+		&i.SuspensionHidesChirps,
+		&i.ShadowBanned,
 	)
 	return i, err
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red FROM users
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, display_name, bio, location, sensitive_content, role, suspended_at, suspended_until, suspension_reason, suspension_hides_chirps, shadow_banned FROM users
 WHERE email =  $1
 `
 
@@ -51,6 +93,77 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Location,
+		&i.SensitiveContent,
+		&i.Role,
+		&i.SuspendedAt,
+		&i.SuspendedUntil,
+		&i.SuspensionReason,
+		&i.SuspensionHidesChirps,
+		&i.ShadowBanned,
+	)
+	return i, err
+}
+
+const getUserByHandle = `-- name: GetUserByHandle :one
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, display_name, bio, location, sensitive_content, role, suspended_at, suspended_until, suspension_reason, suspension_hides_chirps, shadow_banned FROM users
+WHERE lower(handle) = lower($1)
+`
+
+func (q *Queries) GetUserByHandle(ctx context.Context, handle string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByHandle, handle)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Location,
+		&i.SensitiveContent,
+		&i.Role,
+		&i.SuspendedAt,
+		&i.SuspendedUntil,
+		&i.SuspensionReason,
+		&i.SuspensionHidesChirps,
+		&i.ShadowBanned,
+	)
+	return i, err
+}
+
+const getUserByID = `-- name: GetUserByID :one
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, display_name, bio, location, sensitive_content, role, suspended_at, suspended_until, suspension_reason, suspension_hides_chirps, shadow_banned FROM users
+WHERE id = $1
+`
+
+func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByID, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Location,
+		&i.SensitiveContent,
+		&i.Role,
+		&i.SuspendedAt,
+		&i.SuspendedUntil,
+		&i.SuspensionReason,
+		&i.SuspensionHidesChirps,
+		&i.ShadowBanned,
 	)
 	return i, err
 }
@@ -64,23 +177,147 @@ func (q *Queries) Reset(ctx context.Context) error {
 	return err
 }
 
-const updateUser = `-- name: UpdateUser :one
+const setUserShadowBanned = `-- name: SetUserShadowBanned :one
 UPDATE users
-SET email = $2,
-    hashed_password = $3,
+SET shadow_banned = $1,
+    updated_at = NOW()
+WHERE id = $2
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, display_name, bio, location, sensitive_content, role, suspended_at, suspended_until, suspension_reason, suspension_hides_chirps, shadow_banned
+`
+
+type SetUserShadowBannedParams struct {
+	ShadowBanned bool
+	ID           uuid.UUID
+}
+
+func (q *Queries) SetUserShadowBanned(ctx context.Context, arg SetUserShadowBannedParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, setUserShadowBanned, arg.ShadowBanned, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Location,
+		&i.SensitiveContent,
+		&i.Role,
+		&i.SuspendedAt,
+		&i.SuspendedUntil,
+		&i.SuspensionReason,
+		&i.SuspensionHidesChirps,
+		&i.ShadowBanned,
+	)
+	return i, err
+}
+
+const suspendUser = `-- name: SuspendUser :one
+UPDATE users
+SET suspended_at = NOW(),
+    suspended_until = $1,
+    suspension_reason = $2,
+    suspension_hides_chirps = $3,
+    updated_at = NOW()
+WHERE id = $4
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, display_name, bio, location, sensitive_content, role, suspended_at, suspended_until, suspension_reason, suspension_hides_chirps, shadow_banned
+`
+
+type SuspendUserParams struct {
+	SuspendedUntil        sql.NullTime
+	SuspensionReason      string
+	SuspensionHidesChirps bool
+	ID                    uuid.UUID
+}
+
+func (q *Queries) SuspendUser(ctx context.Context, arg SuspendUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, suspendUser,
+		arg.SuspendedUntil,
+		arg.SuspensionReason,
+		arg.SuspensionHidesChirps,
+		arg.ID,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Location,
+		&i.SensitiveContent,
+		&i.Role,
+		&i.SuspendedAt,
+		&i.SuspendedUntil,
+		&i.SuspensionReason,
+		&i.SuspensionHidesChirps,
+		&i.ShadowBanned,
+	)
+	return i, err
+}
+
+const unsuspendUser = `-- name: UnsuspendUser :execrows
+UPDATE users
+SET suspended_at = NULL,
+    suspended_until = NULL,
+    suspension_reason = '',
+    suspension_hides_chirps = FALSE,
     updated_at = NOW()
 WHERE id = $1
-RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red
+    AND suspended_at IS NOT NULL
+`
+
+func (q *Queries) UnsuspendUser(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, unsuspendUser, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateUser = `-- name: UpdateUser :one
+UPDATE users
+SET email = COALESCE($1, email),
+    hashed_password = COALESCE($2, hashed_password),
+    handle = COALESCE($3, handle),
+    display_name = COALESCE($4, display_name),
+    bio = COALESCE($5, bio),
+    location = COALESCE($6, location),
+    sensitive_content = COALESCE($7, sensitive_content),
+    updated_at = NOW()
+WHERE id = $8
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, display_name, bio, location, sensitive_content, role, suspended_at, suspended_until, suspension_reason, suspension_hides_chirps, shadow_banned
 `
 
 type UpdateUserParams struct {
-	ID             uuid.UUID
-	Email          string
-	HashedPassword string
+	Email            sql.NullString
+	HashedPassword   sql.NullString
+	Handle           sql.NullString
+	DisplayName      sql.NullString
+	Bio              sql.NullString
+	Location         sql.NullString
+	SensitiveContent sql.NullString
+	ID               uuid.UUID
 }
 
 func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, updateUser, arg.ID, arg.Email, arg.HashedPassword)
+	row := q.db.QueryRowContext(ctx, updateUser,
+		arg.Email,
+		arg.HashedPassword,
+		arg.Handle,
+		arg.DisplayName,
+		arg.Bio,
+		arg.Location,
+		arg.SensitiveContent,
+		arg.ID,
+	)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -89,6 +326,17 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Location,
+		&i.SensitiveContent,
+		&i.Role,
+		&i.SuspendedAt,
+		&i.SuspendedUntil,
+		&i.SuspensionReason,
+		&i.SuspensionHidesChirps,
+		&i.ShadowBanned,
 	)
 	return i, err
 }
@@ -98,7 +346,8 @@ UPDATE users
 SET is_chirpy_red = TRUE,
     updated_at = NOW()
 WHERE id = $1
-RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red
+    AND NOT is_chirpy_red
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, display_name, bio, location, sensitive_content, role, suspended_at, suspended_until, suspension_reason, suspension_hides_chirps, shadow_banned
 `
 
 func (q *Queries) UpgradeUser(ctx context.Context, id uuid.UUID) (User, error) {
@@ -111,6 +360,17 @@ func (q *Queries) UpgradeUser(ctx context.Context, id uuid.UUID) (User, error) {
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Location,
+		&i.SensitiveContent,
+		&i.Role,
+		&i.SuspendedAt,
+		&i.SuspendedUntil,
+		&i.SuspensionReason,
+		&i.SuspensionHidesChirps,
+		&i.ShadowBanned,
 	)
 	return i, err
 }
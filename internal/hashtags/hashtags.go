@@ -0,0 +1,61 @@
+package hashtags
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const MaxLength = 100
+
+// Extract returns the normalized hashtags in a chirp body, in the order they
+// first appear. A hashtag is a # followed by letters, digits and underscores
+// that contains at least one letter and doesn't sit in the middle of a word,
+// so "#go_lang" counts but "C#" and "#2024" don't.
+func Extract(body string) []string {
+	seen := map[string]bool{}
+	tags := []string{}
+
+	for i := 0; i < len(body); i++ {
+		if body[i] != '#' {
+			continue
+		}
+
+		if i > 0 {
+			prev, _ := utf8.DecodeLastRuneInString(body[:i])
+			if isTagRune(prev) || prev == '#' || prev == '&' || prev == '/' {
+				continue
+			}
+		}
+
+		end := i + 1
+		hasLetter := false
+		for end < len(body) {
+			r, size := utf8.DecodeRuneInString(body[end:])
+			if !isTagRune(r) {
+				break
+			}
+			hasLetter = hasLetter || unicode.IsLetter(r)
+			end += size
+		}
+
+		tag := Normalize(body[i+1 : end])
+		if hasLetter && utf8.RuneCountInString(tag) <= MaxLength && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+		i = end - 1
+	}
+
+	return tags
+}
+
+// Normalize maps the different ways of writing a tag onto the form it is
+// stored under, so #Golang, #golang and "golang" all find the same chirps.
+func Normalize(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(tag, "#"))
+}
+
+func isTagRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) || r == '_'
+}
@@ -0,0 +1,49 @@
+package hashtags
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		expectedTags []string
+	}{
+		{
+			name: "No hashtags",
+			body: "just a chirp",
+			expectedTags: []string{},
+		},
+		{
+			name: "Hashtags are normalized and deduplicated",
+			body: "#Go is great #golang #GO",
+			expectedTags: []string{"go", "golang"},
+		},
+		{
+			name: "Punctuation ends a hashtag",
+			body: "loving #chirpy! and #go_lang, #café.",
+			expectedTags: []string{"chirpy", "go_lang", "café"},
+		},
+		{
+			name: "Hashtags inside words, numbers and links are ignored",
+			body: "C# #2024 x#y https://example.com/#anchor ##double",
+			expectedTags: []string{},
+		},
+		{
+			name: "Numbers with letters are fine",
+			body: "#web3 at the start",
+			expectedTags: []string{"web3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags := Extract(tt.body)
+			if !slices.Equal(tags, tt.expectedTags) {
+				t.Errorf("expected tags %v, got %v", tt.expectedTags, tags)
+			}
+		})
+	}
+}
@@ -0,0 +1,274 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+const (
+	// MaxPixels guards against small files that decode to huge images.
+	MaxPixels     = 40_000_000
+	ThumbnailSize = 320
+	jpegQuality   = 90
+)
+
+var (
+	ErrUnsupportedType = errors.New("unsupported image type")
+	ErrTooManyPixels   = errors.New("image dimensions are too large")
+)
+
+type Image struct {
+	Data        []byte
+	ContentType string
+	Width       int
+	Height      int
+}
+
+type Result struct {
+	Original  Image
+	Thumbnail Image
+}
+
+// Process validates an uploaded image and re-encodes it. Re-encoding drops
+// EXIF and any other metadata, so a JPEG's orientation tag is applied to the
+// pixels first or the picture would come out sideways. The thumbnail fits
+// within ThumbnailSize on both sides and is never larger than the original.
+func Process(data []byte) (Result, error) {
+	contentType := http.DetectContentType(data)
+	if contentType != "image/jpeg" && contentType != "image/png" && contentType != "image/gif" {
+		return Result{}, ErrUnsupportedType
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Result{}, err
+	}
+	if config.Width*config.Height > MaxPixels {
+		return Result{}, ErrTooManyPixels
+	}
+
+	var original Image
+	var img image.Image
+	switch contentType {
+	case "image/gif":
+		// keep every frame so animations survive
+		animation, err := gif.DecodeAll(bytes.NewReader(data))
+		if err != nil {
+			return Result{}, err
+		}
+		var buf bytes.Buffer
+		if err := gif.EncodeAll(&buf, animation); err != nil {
+			return Result{}, err
+		}
+		img = animation.Image[0]
+		original = Image{Data: buf.Bytes(), ContentType: contentType, Width: animation.Config.Width, Height: animation.Config.Height}
+	default:
+		img, _, err = image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return Result{}, err
+		}
+		if contentType == "image/jpeg" {
+			img = orient(img, jpegOrientation(data))
+		}
+		original, err = encode(img, contentType)
+		if err != nil {
+			return Result{}, err
+		}
+	}
+
+	thumbnailType := contentType
+	if thumbnailType == "image/gif" {
+		thumbnailType = "image/png"
+	}
+	width, height := fit(img.Bounds().Dx(), img.Bounds().Dy(), ThumbnailSize)
+	thumbnail, err := encode(resize(img, width, height), thumbnailType)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return Result{Original: original, Thumbnail: thumbnail}, nil
+}
+
+func encode(img image.Image, contentType string) (Image, error) {
+	var buf bytes.Buffer
+	var err error
+	if contentType == "image/jpeg" {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	} else {
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		return Image{}, err
+	}
+
+	return Image{
+		Data: buf.Bytes(),
+		ContentType: contentType,
+		Width: img.Bounds().Dx(),
+		Height: img.Bounds().Dy(),
+	}, nil
+}
+
+// fit scales width and height down to fit within size, keeping the aspect
+// ratio. Images that already fit are left alone.
+func fit(width, height, size int) (int, int) {
+	if width <= size && height <= size {
+		return width, height
+	}
+	if width >= height {
+		return size, max(1, height*size/width)
+	}
+	return max(1, width*size/height), size
+}
+
+// resize scales src to width x height by averaging the block of source
+// pixels behind each destination pixel, which holds up well for the large
+// reductions thumbnails need.
+func resize(src image.Image, width, height int) image.Image {
+	bounds := src.Bounds()
+	if bounds.Dx() == width && bounds.Dy() == height {
+		return src
+	}
+
+	dst := image.NewRGBA64(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*bounds.Dy()/height
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*bounds.Dy()/height)
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*bounds.Dx()/width
+			x1 := max(x0+1, bounds.Min.X+(x+1)*bounds.Dx()/width)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r += uint64(cr)
+					g += uint64(cg)
+					b += uint64(cb)
+					a += uint64(ca)
+					n++
+				}
+			}
+			dst.SetRGBA64(x, y, color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(b / n), A: uint16(a / n)})
+		}
+	}
+
+	return dst
+}
+
+// orient applies an EXIF orientation (1-8) so the pixels read the right way
+// up without the tag.
+func orient(src image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	dstWidth, dstHeight := width, height
+	if orientation >= 5 {
+		dstWidth, dstHeight = height, width
+	}
+
+	dst := image.NewRGBA64(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = width-1-x, y
+			case 3:
+				dx, dy = width-1-x, height-1-y
+			case 4:
+				dx, dy = x, height-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = height-1-y, x
+			case 7:
+				dx, dy = height-1-y, width-1-x
+			case 8:
+				dx, dy = y, width-1-x
+			}
+			dst.Set(dx, dy, src.At(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+
+	return dst
+}
+
+// jpegOrientation reads the orientation tag from a JPEG's EXIF block,
+// returning 1 (upright) if there isn't one.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		if marker == 0xDA || marker == 0xD9 {
+			// start of scan or end of image: no more metadata
+			return 1
+		}
+
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		if size < 2 || i+2+size > len(data) {
+			return 1
+		}
+		segment := data[i+4 : i+2+size]
+		if marker == 0xE1 && len(segment) >= 6 && string(segment[:6]) == "Exif\x00\x00" {
+			return tiffOrientation(segment[6:])
+		}
+		i += 2 + size
+	}
+
+	return 1
+}
+
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	offset := int(order.Uint32(tiff[4:]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 1
+	}
+
+	entries := int(order.Uint16(tiff[offset:]))
+	for i := 0; i < entries; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			orientation := int(order.Uint16(tiff[entry+8:]))
+			if orientation < 1 || orientation > 8 {
+				return 1
+			}
+			return orientation
+		}
+	}
+
+	return 1
+}
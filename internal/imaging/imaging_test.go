@@ -0,0 +1,145 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func makeImage(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+// withOrientation inserts an EXIF block carrying the given orientation
+// straight after a JPEG's start-of-image marker.
+func withOrientation(jpegData []byte, orientation uint16) []byte {
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")
+	tiff = binary.BigEndian.AppendUint16(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, 0x0112)
+	tiff = binary.BigEndian.AppendUint16(tiff, 3)
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := []byte{0xFF, 0xE1}
+	app1 = binary.BigEndian.AppendUint16(app1, uint16(len(segment)+2))
+	app1 = append(app1, segment...)
+
+	out := append([]byte{}, jpegData[:2]...)
+	out = append(out, app1...)
+	return append(out, jpegData[2:]...)
+}
+
+func TestProcessPNG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, makeImage(800, 400)); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Process(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	if result.Original.ContentType != "image/png" || result.Original.Width != 800 || result.Original.Height != 400 {
+		t.Errorf("unexpected original: %s %dx%d", result.Original.ContentType, result.Original.Width, result.Original.Height)
+	}
+	if result.Thumbnail.Width != 320 || result.Thumbnail.Height != 160 {
+		t.Errorf("expected a 320x160 thumbnail, got %dx%d", result.Thumbnail.Width, result.Thumbnail.Height)
+	}
+}
+
+func TestProcessJPEGStripsEXIFAndAppliesOrientation(t *testing.T) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, makeImage(200, 100), nil); err != nil {
+		t.Fatal(err)
+	}
+	data := withOrientation(buf.Bytes(), 6)
+
+	if orientation := jpegOrientation(data); orientation != 6 {
+		t.Fatalf("expected orientation 6, got %d", orientation)
+	}
+
+	result, err := Process(data)
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	if result.Original.Width != 100 || result.Original.Height != 200 {
+		t.Errorf("expected the image to be rotated to 100x200, got %dx%d", result.Original.Width, result.Original.Height)
+	}
+	if bytes.Contains(result.Original.Data, []byte("Exif")) {
+		t.Error("expected EXIF data to be stripped")
+	}
+	if orientation := jpegOrientation(result.Original.Data); orientation != 1 {
+		t.Errorf("expected no orientation tag after processing, got %d", orientation)
+	}
+}
+
+func TestProcessSmallImageKeepsSize(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, makeImage(50, 80)); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Process(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if result.Thumbnail.Width != 50 || result.Thumbnail.Height != 80 {
+		t.Errorf("expected the thumbnail to stay 50x80, got %dx%d", result.Thumbnail.Width, result.Thumbnail.Height)
+	}
+}
+
+func TestProcessRejectsNonImages(t *testing.T) {
+	_, err := Process([]byte("definitely not an image"))
+	if !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("expected ErrUnsupportedType, got %v", err)
+	}
+}
+
+func TestOrient(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	marked := color.RGBA{R: 255, A: 255}
+	src.Set(0, 0, marked)
+
+	tests := []struct {
+		orientation int
+		width       int
+		height      int
+		x, y        int
+	}{
+		{orientation: 1, width: 3, height: 2, x: 0, y: 0},
+		{orientation: 2, width: 3, height: 2, x: 2, y: 0},
+		{orientation: 3, width: 3, height: 2, x: 2, y: 1},
+		{orientation: 4, width: 3, height: 2, x: 0, y: 1},
+		{orientation: 5, width: 2, height: 3, x: 0, y: 0},
+		{orientation: 6, width: 2, height: 3, x: 1, y: 0},
+		{orientation: 7, width: 2, height: 3, x: 1, y: 2},
+		{orientation: 8, width: 2, height: 3, x: 0, y: 2},
+	}
+
+	for _, tt := range tests {
+		dst := orient(src, tt.orientation)
+		if dst.Bounds().Dx() != tt.width || dst.Bounds().Dy() != tt.height {
+			t.Errorf("orientation %d: expected %dx%d, got %dx%d", tt.orientation, tt.width, tt.height, dst.Bounds().Dx(), dst.Bounds().Dy())
+			continue
+		}
+		r, _, _, _ := dst.At(tt.x, tt.y).RGBA()
+		if r != 0xffff {
+			t.Errorf("orientation %d: expected the top-left pixel to end up at (%d, %d)", tt.orientation, tt.x, tt.y)
+		}
+	}
+}
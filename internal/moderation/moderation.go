@@ -0,0 +1,296 @@
+package moderation
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Rule kinds. A word rule matches a whole word or phrase, so "fornax" catches
+// "Fornax!" but not "fornaxes". A regex rule is an RE2 expression matched
+// anywhere in the text.
+const (
+	KindWord  = "word"
+	KindRegex = "regex"
+)
+
+// Rule actions. Mask replaces the matched text with asterisks, reject
+// refuses the text outright, and hold lets it through unchanged but marks
+// it for a moderator to look at.
+const (
+	ActionMask   = "mask"
+	ActionReject = "reject"
+	ActionHold   = "hold"
+)
+
+const MaxPatternLength = 500
+
+const mask = "****"
+
+type Rule struct {
+	ID      uuid.UUID
+	Kind    string
+	Pattern string
+	Action  string
+}
+
+type Result struct {
+	// Text is the input with every masked match replaced.
+	Text     string
+	Rejected bool
+	Held     bool
+	// Matched lists the rules that matched, in the order they were loaded.
+	Matched []uuid.UUID
+}
+
+type compiledRule struct {
+	Rule
+	re        *regexp.Regexp
+	wholeWord bool
+}
+
+// Compile checks a rule and prepares it for matching. Matching is always
+// case-insensitive across Unicode, and word patterns ignore punctuation in
+// the same way the text does.
+func Compile(rule Rule) (compiledRule, error) {
+	switch rule.Action {
+	case ActionMask, ActionReject, ActionHold:
+	default:
+		return compiledRule{}, errors.New("action must be mask, reject or hold")
+	}
+
+	if utf8.RuneCountInString(rule.Pattern) > MaxPatternLength {
+		return compiledRule{}, fmt.Errorf("pattern must be at most %d characters", MaxPatternLength)
+	}
+
+	switch rule.Kind {
+	case KindWord:
+		words := strings.Fields(stripPunctuation(rule.Pattern))
+		if len(words) == 0 {
+			return compiledRule{}, errors.New("word pattern must contain a letter or digit")
+		}
+		for i, word := range words {
+			words[i] = regexp.QuoteMeta(word)
+		}
+		re := regexp.MustCompile(`(?i)` + strings.Join(words, `\s+`))
+		return compiledRule{Rule: rule, re: re, wholeWord: true}, nil
+
+	case KindRegex:
+		re, err := regexp.Compile(`(?i)` + rule.Pattern)
+		if err != nil {
+			return compiledRule{}, fmt.Errorf("invalid regex: %w", err)
+		}
+		if re.MatchString("") {
+			return compiledRule{}, errors.New("regex must not match empty text")
+		}
+		return compiledRule{Rule: rule, re: re}, nil
+	}
+
+	return compiledRule{}, errors.New("kind must be word or regex")
+}
+
+// Engine holds the current set of rules. It is safe for concurrent use, and
+// Load can swap the rules while chirps are being checked.
+type Engine struct {
+	mu    sync.RWMutex
+	rules []compiledRule
+}
+
+func NewEngine() *Engine {
+	return &Engine{}
+}
+
+// Load replaces the engine's rules. If any rule fails to compile, none of
+// them are loaded and the previous rules stay in place.
+func (e *Engine) Load(rules []Rule) error {
+	compiled := make([]compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		c, err := Compile(rule)
+		if err != nil {
+			return fmt.Errorf("rule %s: %w", rule.ID, err)
+		}
+		compiled = append(compiled, c)
+	}
+
+	e.mu.Lock()
+	e.rules = compiled
+	e.mu.Unlock()
+	return nil
+}
+
+// Check runs text through every rule. Punctuation is ignored while
+// matching, both as if it weren't there ("ker-fuffle") and as if it were a
+// space ("fornax,kerfuffle"), so it can't be used to slip a term past a
+// rule; masking only replaces the letters that matched, so "Kerfuffle!"
+// becomes "****!". Apostrophes always separate words, so contractions like
+// "he'll" and "I'll" aren't read as "hell" and "ill". Regex rules are matched against the text as written as
+// well, so that patterns with punctuation in them, like `evil\.com`, work.
+func (e *Engine) Check(text string) Result {
+	e.mu.RLock()
+	rules := e.rules
+	e.mu.RUnlock()
+
+	result := Result{Text: text}
+	if len(rules) == 0 {
+		return result
+	}
+
+	views := []normalized{normalize(text, false), normalize(text, true)}
+	original := verbatim(text)
+	masked := []span{}
+	for _, rule := range rules {
+		spans := []span{}
+		for _, view := range views {
+			spans = append(spans, view.find(rule)...)
+		}
+		if !rule.wholeWord {
+			spans = append(spans, original.find(rule)...)
+		}
+		if len(spans) == 0 {
+			continue
+		}
+
+		result.Matched = append(result.Matched, rule.ID)
+		switch rule.Action {
+		case ActionReject:
+			result.Rejected = true
+		case ActionHold:
+			result.Held = true
+		case ActionMask:
+			masked = append(masked, spans...)
+		}
+	}
+
+	result.Text = applyMask(text, masked)
+	return result
+}
+
+// span is a byte range in the original text.
+type span struct {
+	start, end int
+}
+
+// normalized is text with its punctuation removed or turned into spaces,
+// along with where each of its bytes came from in the original.
+type normalized struct {
+	text   string
+	starts []int
+	ends   []int
+}
+
+func normalize(text string, punctuationAsSpace bool) normalized {
+	var b strings.Builder
+	n := normalized{}
+	for i, r := range text {
+		_, originalSize := utf8.DecodeRuneInString(text[i:])
+		if isApostrophe(r) {
+			r = ' '
+		} else if unicode.IsPunct(r) {
+			if !punctuationAsSpace {
+				continue
+			}
+			r = ' '
+		}
+
+		b.WriteRune(r)
+		for range utf8.RuneLen(r) {
+			n.starts = append(n.starts, i)
+			n.ends = append(n.ends, i+originalSize)
+		}
+	}
+
+	n.text = b.String()
+	return n
+}
+
+// verbatim is text as written, for rules that match punctuation themselves.
+func verbatim(text string) normalized {
+	n := normalized{text: text, starts: make([]int, len(text)), ends: make([]int, len(text))}
+	for i := range len(text) {
+		n.starts[i] = i
+		n.ends[i] = i + 1
+	}
+	return n
+}
+
+func (n normalized) find(rule compiledRule) []span {
+	spans := []span{}
+	for _, m := range rule.re.FindAllStringIndex(n.text, -1) {
+		if m[0] == m[1] {
+			continue
+		}
+		if rule.wholeWord && !n.atWordBoundaries(m[0], m[1]) {
+			continue
+		}
+		spans = append(spans, span{start: n.starts[m[0]], end: n.ends[m[1]-1]})
+	}
+	return spans
+}
+
+func (n normalized) atWordBoundaries(start, end int) bool {
+	if start > 0 {
+		before, _ := utf8.DecodeLastRuneInString(n.text[:start])
+		if isWordRune(before) {
+			return false
+		}
+	}
+	if end < len(n.text) {
+		after, _ := utf8.DecodeRuneInString(n.text[end:])
+		if isWordRune(after) {
+			return false
+		}
+	}
+	return true
+}
+
+func applyMask(text string, spans []span) string {
+	if len(spans) == 0 {
+		return text
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	var b strings.Builder
+	last := 0
+	for _, s := range spans {
+		if s.start < last {
+			// overlaps the previous mask, so extend it instead
+			if s.end > last {
+				last = s.end
+			}
+			continue
+		}
+		b.WriteString(text[last:s.start])
+		b.WriteString(mask)
+		last = s.end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+func stripPunctuation(s string) string {
+	return strings.Map(func(r rune) rune {
+		if isApostrophe(r) {
+			return ' '
+		}
+		if unicode.IsPunct(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+func isApostrophe(r rune) bool {
+	return r == '\'' || r == '’'
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r)
+}
@@ -0,0 +1,186 @@
+package moderation
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestCheck(t *testing.T) {
+	rules := []Rule{
+		{ID: uuid.New(), Kind: KindWord, Pattern: "kerfuffle", Action: ActionMask},
+		{ID: uuid.New(), Kind: KindWord, Pattern: "fornax", Action: ActionMask},
+		{ID: uuid.New(), Kind: KindWord, Pattern: "buy now", Action: ActionReject},
+		{ID: uuid.New(), Kind: KindRegex, Pattern: `free\s*crypto`, Action: ActionHold},
+		{ID: uuid.New(), Kind: KindRegex, Pattern: `evil\.com`, Action: ActionReject},
+		{ID: uuid.New(), Kind: KindWord, Pattern: "hell", Action: ActionMask},
+		{ID: uuid.New(), Kind: KindWord, Pattern: "ill", Action: ActionHold},
+	}
+
+	engine := NewEngine()
+	if err := engine.Load(rules); err != nil {
+		t.Fatalf("unexpected error loading rules: %v", err)
+	}
+
+	tests := []struct {
+		name             string
+		text             string
+		expectedText     string
+		expectedRejected bool
+		expectedHeld     bool
+		expectedMatched  int
+	}{
+		{
+			name: "No matches",
+			text: "just a chirp",
+			expectedText: "just a chirp",
+		},
+		{
+			name: "Case is ignored and punctuation is kept",
+			text: "What a Kerfuffle! Fornax.",
+			expectedText: "What a ****! ****.",
+			expectedMatched: 2,
+		},
+		{
+			name: "Punctuation inside a word doesn't hide it",
+			text: "such a ker-fuffle",
+			expectedText: "such a ****",
+			expectedMatched: 1,
+		},
+		{
+			name: "Punctuation between words separates them",
+			text: "fornax,kerfuffle",
+			expectedText: "****,****",
+			expectedMatched: 2,
+		},
+		{
+			name: "Words inside other words are left alone",
+			text: "fornaxes and kerfuffled",
+			expectedText: "fornaxes and kerfuffled",
+		},
+		{
+			name: "Contractions aren't joined into other words",
+			text: "He'll be fine, I’ll be there",
+			expectedText: "He'll be fine, I’ll be there",
+		},
+		{
+			name: "Words next to an apostrophe still match",
+			text: "what the hell's going on",
+			expectedText: "what the ****'s going on",
+			expectedMatched: 1,
+		},
+		{
+			name: "Unicode case folding",
+			text: "KERFUFFLE ÇA",
+			expectedText: "**** ÇA",
+			expectedMatched: 1,
+		},
+		{
+			name: "Phrases match across spacing",
+			text: "Buy   NOW!",
+			expectedText: "Buy   NOW!",
+			expectedRejected: true,
+			expectedMatched: 1,
+		},
+		{
+			name: "Regex rules match anywhere",
+			text: "get yourFREECRYPTO here",
+			expectedText: "get yourFREECRYPTO here",
+			expectedHeld: true,
+			expectedMatched: 1,
+		},
+		{
+			name: "Regex rules can match punctuation",
+			text: "visit evil.com now",
+			expectedText: "visit evil.com now",
+			expectedRejected: true,
+			expectedMatched: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := engine.Check(tt.text)
+			if result.Text != tt.expectedText {
+				t.Errorf("expected text %q, got %q", tt.expectedText, result.Text)
+			}
+			if result.Rejected != tt.expectedRejected {
+				t.Errorf("expected rejected %v, got %v", tt.expectedRejected, result.Rejected)
+			}
+			if result.Held != tt.expectedHeld {
+				t.Errorf("expected held %v, got %v", tt.expectedHeld, result.Held)
+			}
+			if len(result.Matched) != tt.expectedMatched {
+				t.Errorf("expected %d matched rules, got %d", tt.expectedMatched, len(result.Matched))
+			}
+		})
+	}
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name        string
+		rule        Rule
+		expectError bool
+	}{
+		{
+			name: "Word rule",
+			rule: Rule{Kind: KindWord, Pattern: "kerfuffle", Action: ActionMask},
+		},
+		{
+			name: "Word rule without letters",
+			rule: Rule{Kind: KindWord, Pattern: "!!", Action: ActionMask},
+			expectError: true,
+		},
+		{
+			name: "Invalid regex",
+			rule: Rule{Kind: KindRegex, Pattern: "(unclosed", Action: ActionReject},
+			expectError: true,
+		},
+		{
+			name: "Regex matching empty text",
+			rule: Rule{Kind: KindRegex, Pattern: "a*", Action: ActionReject},
+			expectError: true,
+		},
+		{
+			name: "Unknown kind",
+			rule: Rule{Kind: "glob", Pattern: "kerfuffle", Action: ActionMask},
+			expectError: true,
+		},
+		{
+			name: "Unknown action",
+			rule: Rule{Kind: KindWord, Pattern: "kerfuffle", Action: "ban"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.rule)
+			if (err != nil) != tt.expectError {
+				t.Errorf("expected error %v, got %v", tt.expectError, err)
+			}
+		})
+	}
+}
+
+func TestLoadKeepsRulesOnError(t *testing.T) {
+	engine := NewEngine()
+	err := engine.Load([]Rule{{ID: uuid.New(), Kind: KindWord, Pattern: "kerfuffle", Action: ActionMask}})
+	if err != nil {
+		t.Fatalf("unexpected error loading rules: %v", err)
+	}
+
+	err = engine.Load([]Rule{
+		{ID: uuid.New(), Kind: KindWord, Pattern: "fornax", Action: ActionMask},
+		{ID: uuid.New(), Kind: KindRegex, Pattern: "(", Action: ActionMask},
+	})
+	if err == nil {
+		t.Fatal("expected an error loading an invalid rule")
+	}
+
+	result := engine.Check("kerfuffle fornax")
+	if result.Text != "**** fornax" {
+		t.Errorf("expected the previous rules to stay loaded, got %q", result.Text)
+	}
+}
@@ -0,0 +1,191 @@
+package pagination
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+type Direction string
+
+const (
+	Next Direction = "next"
+	Prev Direction = "prev"
+)
+
+// Cursor marks a position in a list ordered by (created_at, id). It is
+// handed to clients as an opaque string and only ever decoded by the server.
+type Cursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uuid.UUID `json:"id"`
+	Rank      float32   `json:"r,omitempty"`
+	Direction Direction `json:"d"`
+}
+
+func EncodeCursor(cursor Cursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(s string) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, errors.New("malformed cursor")
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return Cursor{}, errors.New("malformed cursor")
+	}
+
+	if cursor.ID == uuid.Nil || cursor.CreatedAt.IsZero() {
+		return Cursor{}, errors.New("incomplete cursor")
+	}
+
+	if cursor.Direction != Next && cursor.Direction != Prev {
+		return Cursor{}, errors.New("unknown cursor direction")
+	}
+
+	return cursor, nil
+}
+
+// NullTime and NullID return the cursor's position as query arguments, which
+// are NULL when there is no cursor.
+func (c *Cursor) NullTime() sql.NullTime {
+	if c == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: c.CreatedAt, Valid: true}
+}
+
+func (c *Cursor) NullID() uuid.NullUUID {
+	if c == nil {
+		return uuid.NullUUID{}
+	}
+	return uuid.NullUUID{UUID: c.ID, Valid: true}
+}
+
+// Params is the page a client asked for. Cursor is nil for the first page.
+type Params struct {
+	Limit  int32
+	Cursor *Cursor
+}
+
+// ParseParams reads the limit and cursor query parameters.
+func ParseParams(query url.Values) (Params, error) {
+	limit, err := ParseLimit(query.Get("limit"))
+	if err != nil {
+		return Params{}, err
+	}
+
+	params := Params{Limit: limit}
+	if s := query.Get("cursor"); s != "" {
+		cursor, err := DecodeCursor(s)
+		if err != nil {
+			return Params{}, err
+		}
+		params.Cursor = &cursor
+	}
+
+	return params, nil
+}
+
+// ParseForwardParams is ParseParams for lists that can only be paged
+// forwards, such as those ordered by something other than time.
+func ParseForwardParams(query url.Values) (Params, error) {
+	params, err := ParseParams(query)
+	if err != nil {
+		return Params{}, err
+	}
+
+	if params.Cursor != nil && params.Cursor.Direction != Next {
+		return Params{}, errors.New("this list can only be paged forwards")
+	}
+
+	return params, nil
+}
+
+// ParseLimit reads a page size from a query string value, falling back to
+// DefaultLimit when it is empty and capping it at MaxLimit.
+func ParseLimit(s string) (int32, error) {
+	if s == "" {
+		return DefaultLimit, nil
+	}
+
+	limit, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, errors.New("limit must be a number")
+	}
+
+	if limit < 1 {
+		return 0, errors.New("limit must be positive")
+	}
+
+	return int32(min(limit, MaxLimit)), nil
+}
+
+// LinkHeader builds an RFC 8288 Link header value for the next and previous
+// pages of the list served at u. Empty cursors are left out.
+func LinkHeader(u *url.URL, nextCursor, prevCursor string) string {
+	var links []string
+	if nextCursor != "" {
+		links = append(links, `<`+withCursor(u, nextCursor)+`>; rel="next"`)
+	}
+	if prevCursor != "" {
+		links = append(links, `<`+withCursor(u, prevCursor)+`>; rel="prev"`)
+	}
+	return strings.Join(links, ", ")
+}
+
+func withCursor(u *url.URL, cursor string) string {
+	query := u.Query()
+	query.Set("cursor", cursor)
+	return u.Path + "?" + query.Encode()
+}
+
+// Page trims rows fetched with a limit of limit+1 down to a single page and
+// works out the cursors for its neighbours. from is the cursor the page was
+// requested with, or nil for the first page. Rows fetched for a Prev cursor
+// are expected in reverse order and are flipped back before being returned.
+func Page[T any](rows []T, limit int32, from *Cursor, position func(T) Cursor) (items []T, next, prev string) {
+	more := len(rows) > int(limit)
+	if more {
+		rows = rows[:limit]
+	}
+
+	backwards := from != nil && from.Direction == Prev
+	if backwards {
+		slices.Reverse(rows)
+	}
+
+	if len(rows) == 0 {
+		return rows, "", ""
+	}
+
+	if more || backwards {
+		cursor := position(rows[len(rows)-1])
+		cursor.Direction = Next
+		next = EncodeCursor(cursor)
+	}
+
+	if (more && backwards) || (from != nil && !backwards) {
+		cursor := position(rows[0])
+		cursor.Direction = Prev
+		prev = EncodeCursor(cursor)
+	}
+
+	return rows, next, prev
+}
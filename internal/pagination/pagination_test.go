@@ -0,0 +1,160 @@
+package pagination
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursor := Cursor{
+		CreatedAt: time.Date(2025, 6, 1, 12, 30, 0, 123456000, time.UTC),
+		ID: uuid.New(),
+		Direction: Prev,
+	}
+
+	decoded, err := DecodeCursor(EncodeCursor(cursor))
+	if err != nil {
+		t.Fatalf("DecodeCursor() error = %v", err)
+	}
+
+	if !decoded.CreatedAt.Equal(cursor.CreatedAt) || decoded.ID != cursor.ID || decoded.Direction != cursor.Direction {
+		t.Errorf("expected %+v, got %+v", cursor, decoded)
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	tests := []struct {
+		name        string
+		cursor      string
+		expectedErr bool
+	}{
+		{
+			name: "Valid cursor",
+			cursor: EncodeCursor(Cursor{CreatedAt: time.Now(), ID: uuid.New(), Direction: Next}),
+			expectedErr: false,
+		},
+		{
+			name: "Not base64",
+			cursor: "not a cursor!",
+			expectedErr: true,
+		},
+		{
+			name: "Missing ID",
+			cursor: EncodeCursor(Cursor{CreatedAt: time.Now(), Direction: Next}),
+			expectedErr: true,
+		},
+		{
+			name: "Unknown direction",
+			cursor: EncodeCursor(Cursor{CreatedAt: time.Now(), ID: uuid.New(), Direction: "sideways"}),
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeCursor(tt.cursor)
+			if (err != nil) != tt.expectedErr {
+				t.Errorf("DecodeCursor() error = %v, expectedErr %v", err, tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		name          string
+		limit         string
+		expectedLimit int32
+		expectedErr   bool
+	}{
+		{
+			name: "Empty uses default",
+			limit: "",
+			expectedLimit: DefaultLimit,
+		},
+		{
+			name: "Within range",
+			limit: "5",
+			expectedLimit: 5,
+		},
+		{
+			name: "Capped at maximum",
+			limit: "100000",
+			expectedLimit: MaxLimit,
+		},
+		{
+			name: "Zero",
+			limit: "0",
+			expectedErr: true,
+		},
+		{
+			name: "Not a number",
+			limit: "ten",
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit, err := ParseLimit(tt.limit)
+			if (err != nil) != tt.expectedErr {
+				t.Errorf("ParseLimit() error = %v, expectedErr %v", err, tt.expectedErr)
+				return
+			}
+
+			if limit != tt.expectedLimit {
+				t.Errorf("expected limit %d, got %d", tt.expectedLimit, limit)
+			}
+		})
+	}
+}
+
+func TestLinkHeader(t *testing.T) {
+	u, _ := url.Parse("/api/chirps?sort=desc&cursor=old")
+
+	header := LinkHeader(u, "abc", "")
+	if header != `</api/chirps?cursor=abc&sort=desc>; rel="next"` {
+		t.Errorf("unexpected Link header: %s", header)
+	}
+
+	header = LinkHeader(u, "abc", "def")
+	if !strings.Contains(header, `rel="next"`) || !strings.Contains(header, `cursor=def&sort=desc>; rel="prev"`) {
+		t.Errorf("unexpected Link header: %s", header)
+	}
+
+	if header := LinkHeader(u, "", ""); header != "" {
+		t.Errorf("expected empty Link header, got %s", header)
+	}
+}
+
+func TestPage(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	rows := make([]Cursor, 4)
+	for i := range rows {
+		rows[i] = Cursor{CreatedAt: base.Add(time.Duration(i) * time.Minute), ID: uuid.New()}
+	}
+	position := func(c Cursor) Cursor { return c }
+
+	items, next, prev := Page(rows, 3, nil, position)
+	if len(items) != 3 || next == "" || prev != "" {
+		t.Fatalf("first page: got %d items, next %q, prev %q", len(items), next, prev)
+	}
+
+	items, next, prev = Page(rows[:2], 3, &Cursor{Direction: Next}, position)
+	if len(items) != 2 || next != "" || prev == "" {
+		t.Fatalf("last page: got %d items, next %q, prev %q", len(items), next, prev)
+	}
+
+	reversed := []Cursor{rows[3], rows[2], rows[1], rows[0]}
+	items, next, prev = Page(reversed, 3, &Cursor{Direction: Prev}, position)
+	if len(items) != 3 || next == "" || prev == "" {
+		t.Fatalf("previous page: got %d items, next %q, prev %q", len(items), next, prev)
+	}
+	if items[0] != rows[1] || items[2] != rows[3] {
+		t.Errorf("previous page not restored to list order: %v", items)
+	}
+}
@@ -0,0 +1,55 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/philipreese/chirpy-go/internal/database"
+)
+
+// PostgresStore keeps buckets in the database, so every instance sharing it
+// shares the same limits. Each request costs a short transaction that locks
+// its key's row, so requests for the same key queue up behind each other
+// rather than each spending the same token.
+type PostgresStore struct {
+	db *sql.DB
+	q  *database.Queries
+}
+
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db, q: database.New(db)}
+}
+
+func (s *PostgresStore) Take(ctx context.Context, key string, policy Policy, now time.Time) (Decision, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Decision{}, err
+	}
+	defer tx.Rollback()
+	qtx := s.q.WithTx(tx)
+
+	// a bucket that has just been created comes back with a zero
+	// updated_at, which take treats as a new, full bucket
+	row, err := qtx.LockRateLimitBucket(ctx, key)
+	if err != nil {
+		return Decision{}, err
+	}
+
+	bucket, decision := policy.take(Bucket{Tokens: row.Tokens, UpdatedAt: row.UpdatedAt}, now)
+	err = qtx.SaveRateLimitBucket(ctx, database.SaveRateLimitBucketParams{
+		Key: key,
+		Tokens: bucket.Tokens,
+		UpdatedAt: bucket.UpdatedAt,
+		FullAt: policy.fullAt(bucket),
+	})
+	if err != nil {
+		return Decision{}, err
+	}
+
+	return decision, tx.Commit()
+}
+
+func (s *PostgresStore) Sweep(ctx context.Context, now time.Time) error {
+	return s.q.DeleteFullRateLimitBuckets(ctx, now)
+}
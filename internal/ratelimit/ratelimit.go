@@ -0,0 +1,223 @@
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// KeyFunc picks out who a request counts against, such as its IP address or
+// the user signed in. It returns "" when the request carries no such key.
+type KeyFunc func(req *http.Request) string
+
+// Policy is a token bucket: each key starts with Limit requests and gets
+// them back steadily over Period, so Limit requests can be made at once and
+// Limit per Period on average after that.
+type Policy struct {
+	// Name keeps each policy's buckets apart, so two routes can share a
+	// policy's limit by sharing its name.
+	Name   string
+	Limit  int
+	Period time.Duration
+	Key    KeyFunc
+}
+
+// Bucket is what a store keeps per key between requests.
+type Bucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+// Decision is the outcome of taking a token from a bucket.
+type Decision struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter is how long until the next request would be allowed, zero
+	// if it would be now.
+	RetryAfter time.Duration
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+}
+
+// Store keeps buckets. Take must refill, check and spend a bucket as one
+// step, however many requests for the same key arrive at once.
+type Store interface {
+	Take(ctx context.Context, key string, policy Policy, now time.Time) (Decision, error)
+	// Sweep drops buckets that have filled back up, since a full bucket is
+	// the same as none at all.
+	Sweep(ctx context.Context, now time.Time) error
+}
+
+// take refills a bucket for the time since it was last used and spends a
+// token from it if there is one. A new bucket is the zero Bucket.
+func (p Policy) take(bucket Bucket, now time.Time) (Bucket, Decision) {
+	limit := float64(p.Limit)
+	rate := limit / p.Period.Seconds()
+
+	if bucket.UpdatedAt.IsZero() {
+		bucket.Tokens = limit
+	} else if elapsed := now.Sub(bucket.UpdatedAt).Seconds(); elapsed > 0 {
+		bucket.Tokens = math.Min(limit, bucket.Tokens + elapsed*rate)
+	}
+	if now.After(bucket.UpdatedAt) {
+		bucket.UpdatedAt = now
+	}
+
+	decision := Decision{Limit: p.Limit}
+	if bucket.Tokens >= 1 {
+		bucket.Tokens--
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = seconds((1 - bucket.Tokens) / rate)
+	}
+	decision.Remaining = int(bucket.Tokens)
+	decision.Reset = seconds((limit - bucket.Tokens) / rate)
+
+	return bucket, decision
+}
+
+// fullAt is when a bucket will have filled back up.
+func (p Policy) fullAt(bucket Bucket) time.Time {
+	rate := float64(p.Limit) / p.Period.Seconds()
+	return bucket.UpdatedAt.Add(seconds((float64(p.Limit) - bucket.Tokens) / rate))
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// Limiter is middleware that applies policies to the handlers it wraps.
+type Limiter struct {
+	store Store
+}
+
+func NewLimiter(store Store) *Limiter {
+	return &Limiter{store: store}
+}
+
+// Limit wraps next with a policy. Every response says where the caller
+// stands with RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset and
+// RateLimit-Policy headers, and requests over the limit get a 429 with a
+// Retry-After header instead of reaching next. Requests the policy has no
+// key for, and any the store fails on, are let through; a rate limiter
+// that's down shouldn't take the API down with it.
+func (l *Limiter) Limit(policy Policy, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := policy.Key(r)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		decision, err := l.store.Take(r.Context(), policy.Name + ":" + key, policy, time.Now().UTC())
+		if err != nil {
+			log.Printf("Couldn't check rate limit %s: %v", policy.Name, err)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(decision.Limit))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+		w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(decision.Reset)))
+		w.Header().Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", policy.Limit, ceilSeconds(policy.Period)))
+
+		if !decision.Allowed {
+			retryAfter := max(ceilSeconds(decision.RetryAfter), 1)
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			response, _ := json.Marshal(map[string]string{
+				"error": fmt.Sprintf("Rate limit exceeded: try again in %d seconds", retryAfter),
+			})
+			w.Write(response)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// ByIP keys requests by the address they came from. Behind a trusted proxy
+// that's the last address in X-Forwarded-For, the one the proxy itself
+// added; anything before it was sent by the client and can't be trusted.
+func ByIP(trustProxy bool) KeyFunc {
+	return func(req *http.Request) string {
+		if trustProxy {
+			if forwarded := req.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+				hops := strings.Split(forwarded[len(forwarded)-1], ",")
+				if ip := strings.TrimSpace(hops[len(hops)-1]); ip != "" {
+					return "ip:" + ip
+				}
+			}
+		}
+
+		host, _, err := net.SplitHostPort(req.RemoteAddr)
+		if err != nil {
+			host = req.RemoteAddr
+		}
+		return "ip:" + host
+	}
+}
+
+// FirstOf keys requests by the first of keys that has one for them, such as
+// the user signed in or else their IP address.
+func FirstOf(keys ...KeyFunc) KeyFunc {
+	return func(req *http.Request) string {
+		for _, key := range keys {
+			if k := key(req); k != "" {
+				return k
+			}
+		}
+		return ""
+	}
+}
+
+// MemoryStore keeps buckets in memory. Its limits are per instance, so
+// running several instances behind a load balancer multiplies them.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]memoryBucket
+}
+
+type memoryBucket struct {
+	Bucket
+	fullAt time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]memoryBucket{}}
+}
+
+func (s *MemoryStore) Take(ctx context.Context, key string, policy Policy, now time.Time) (Decision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bucket, decision := policy.take(s.buckets[key].Bucket, now)
+	s.buckets[key] = memoryBucket{Bucket: bucket, fullAt: policy.fullAt(bucket)}
+	return decision, nil
+}
+
+func (s *MemoryStore) Sweep(ctx context.Context, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, bucket := range s.buckets {
+		if !bucket.fullAt.After(now) {
+			delete(s.buckets, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTake(t *testing.T) {
+	policy := Policy{Name: "test", Limit: 5, Period: 10 * time.Second}
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name              string
+		bucket            Bucket
+		now               time.Time
+		expectAllowed     bool
+		expectedRemaining int
+		expectedRetry     time.Duration
+		expectedReset     time.Duration
+	}{
+		{
+			name: "A new bucket starts full",
+			now: start,
+			expectAllowed: true,
+			expectedRemaining: 4,
+			expectedReset: 2 * time.Second,
+		},
+		{
+			name: "An empty bucket turns requests away",
+			bucket: Bucket{Tokens: 0, UpdatedAt: start},
+			now: start,
+			expectedRetry: 2 * time.Second,
+			expectedReset: 10 * time.Second,
+		},
+		{
+			name: "Tokens come back over time",
+			bucket: Bucket{Tokens: 0, UpdatedAt: start},
+			now: start.Add(5 * time.Second),
+			expectAllowed: true,
+			expectedRemaining: 1,
+			expectedReset: 7 * time.Second,
+		},
+		{
+			name: "A bucket never fills past its limit",
+			bucket: Bucket{Tokens: 2, UpdatedAt: start},
+			now: start.Add(time.Hour),
+			expectAllowed: true,
+			expectedRemaining: 4,
+			expectedReset: 2 * time.Second,
+		},
+		{
+			name: "A clock running behind doesn't drain the bucket",
+			bucket: Bucket{Tokens: 3, UpdatedAt: start},
+			now: start.Add(-time.Minute),
+			expectAllowed: true,
+			expectedRemaining: 2,
+			expectedReset: 6 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, decision := policy.take(tt.bucket, tt.now)
+			if decision.Allowed != tt.expectAllowed {
+				t.Errorf("expected allowed %v, got %v", tt.expectAllowed, decision.Allowed)
+			}
+			if decision.Remaining != tt.expectedRemaining {
+				t.Errorf("expected %d remaining, got %d", tt.expectedRemaining, decision.Remaining)
+			}
+			if decision.RetryAfter != tt.expectedRetry {
+				t.Errorf("expected retry after %v, got %v", tt.expectedRetry, decision.RetryAfter)
+			}
+			if decision.Reset != tt.expectedReset {
+				t.Errorf("expected reset %v, got %v", tt.expectedReset, decision.Reset)
+			}
+		})
+	}
+}
+
+func TestLimit(t *testing.T) {
+	store := NewMemoryStore()
+	limiter := NewLimiter(store)
+	policy := Policy{Name: "login", Limit: 2, Period: time.Minute, Key: ByIP(false)}
+	handler := limiter.Limit(policy, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	send := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/login", nil)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i, expectedRemaining := range []string{"1", "0"} {
+		rec := send("10.0.0.1:1234")
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("request %d: expected status %d, got %d", i, http.StatusNoContent, rec.Code)
+		}
+		if got := rec.Header().Get("RateLimit-Remaining"); got != expectedRemaining {
+			t.Errorf("request %d: expected RateLimit-Remaining %s, got %s", i, expectedRemaining, got)
+		}
+	}
+
+	rec := send("10.0.0.1:5678")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status %d, got %d", http.StatusTooManyRequests, rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "30" {
+		t.Errorf("expected Retry-After 30, got %s", got)
+	}
+	if got := rec.Header().Get("RateLimit-Policy"); got != "2;w=60" {
+		t.Errorf("expected RateLimit-Policy 2;w=60, got %s", got)
+	}
+
+	if rec := send("10.0.0.2:1234"); rec.Code != http.StatusNoContent {
+		t.Errorf("expected another address to have its own limit, got status %d", rec.Code)
+	}
+
+	if err := store.Sweep(context.Background(), time.Now().Add(2 * time.Minute)); err != nil {
+		t.Fatalf("Sweep failed: %v", err)
+	}
+	if len(store.buckets) != 0 {
+		t.Errorf("expected full buckets to be swept, got %d left", len(store.buckets))
+	}
+}
+
+func TestKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      KeyFunc
+		headers  map[string]string
+		expected string
+	}{
+		{
+			name: "IP from the connection",
+			key: ByIP(false),
+			headers: map[string]string{"X-Forwarded-For": "1.2.3.4"},
+			expected: "ip:192.0.2.1",
+		},
+		{
+			name: "IP from the last proxy hop",
+			key: ByIP(true),
+			headers: map[string]string{"X-Forwarded-For": "6.6.6.6, 1.2.3.4"},
+			expected: "ip:1.2.3.4",
+		},
+		{
+			name: "Falls back to the next key",
+			key: FirstOf(func(*http.Request) string { return "" }, ByIP(false)),
+			expected: "ip:192.0.2.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			if got := tt.key(req); got != tt.expected {
+				t.Errorf("expected key %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
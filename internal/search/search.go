@@ -0,0 +1,92 @@
+package search
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// ParseQuery turns a user-supplied search string into a Postgres tsquery
+// expression. Words are ANDed together, "quoted phrases" must match in
+// order and a trailing * makes a word match as a prefix:
+//
+//	"morning coffee" espress*  =>  (morning <-> coffee) & espress:*
+func ParseQuery(q string) (string, error) {
+	var terms []string
+	for _, token := range tokenize(q) {
+		var term string
+		if token.phrase {
+			term = phrase(words(token.text), false)
+		} else {
+			prefix := strings.HasSuffix(token.text, "*")
+			term = phrase(words(token.text), prefix)
+		}
+		if term != "" {
+			terms = append(terms, term)
+		}
+	}
+
+	if len(terms) == 0 {
+		return "", errors.New("search query has no searchable words")
+	}
+
+	return strings.Join(terms, " & "), nil
+}
+
+type token struct {
+	text   string
+	phrase bool
+}
+
+func tokenize(q string) []token {
+	var tokens []token
+	var current strings.Builder
+	inQuotes := false
+
+	flush := func(phrase bool) {
+		if current.Len() > 0 {
+			tokens = append(tokens, token{text: current.String(), phrase: phrase})
+			current.Reset()
+		}
+	}
+
+	for _, r := range q {
+		switch {
+		case r == '"':
+			flush(inQuotes)
+			inQuotes = !inQuotes
+		case unicode.IsSpace(r) && !inQuotes:
+			flush(false)
+		default:
+			current.WriteRune(r)
+		}
+	}
+	// an unterminated quote still counts as a phrase
+	flush(inQuotes)
+
+	return tokens
+}
+
+// words splits text into lowercase runs of letters and digits, dropping
+// anything that would otherwise be tsquery syntax.
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func phrase(words []string, prefix bool) string {
+	if len(words) == 0 {
+		return ""
+	}
+
+	if prefix {
+		words[len(words)-1] += ":*"
+	}
+
+	if len(words) == 1 {
+		return words[0]
+	}
+
+	return "(" + strings.Join(words, " <-> ") + ")"
+}
@@ -0,0 +1,62 @@
+package search
+
+import "testing"
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		expectedQuery string
+		expectedErr   bool
+	}{
+		{
+			name: "Single word",
+			query: "Chirpy",
+			expectedQuery: "chirpy",
+		},
+		{
+			name: "Words are ANDed",
+			query: "hello   world",
+			expectedQuery: "hello & world",
+		},
+		{
+			name: "Quoted phrase",
+			query: `"morning coffee" time`,
+			expectedQuery: "(morning <-> coffee) & time",
+		},
+		{
+			name: "Prefix",
+			query: "espress*",
+			expectedQuery: "espress:*",
+		},
+		{
+			name: "Syntax characters are stripped",
+			query: "a&b | !c:*",
+			expectedQuery: "(a <-> b) & c:*",
+		},
+		{
+			name: "Unterminated quote",
+			query: `"good night`,
+			expectedQuery: "(good <-> night)",
+		},
+		{
+			name: "Nothing searchable",
+			query: `!! "" *`,
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := ParseQuery(tt.query)
+			if (err != nil) != tt.expectedErr {
+				t.Errorf("ParseQuery() error = %v, expectedErr %v", err, tt.expectedErr)
+				return
+			}
+
+			if query != tt.expectedQuery {
+				t.Errorf("expected query %q, got %q", tt.expectedQuery, query)
+			}
+		})
+	}
+}
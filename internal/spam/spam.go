@@ -0,0 +1,270 @@
+package spam
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash/fnv"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Actions, from least to most severe. Throttle turns the chirp away for now
+// but lets the author try again later, hold accepts it into the moderation
+// queue, and reject refuses it outright.
+const (
+	ActionAllow    = "allow"
+	ActionThrottle = "throttle"
+	ActionHold     = "hold"
+	ActionReject   = "reject"
+)
+
+// shingleSize is how many words make up a shingle. Three is enough that
+// sharing one shingle means something, and small enough that a couple of
+// changed words still leave most of them in common.
+const shingleSize = 3
+
+var linkPattern = regexp.MustCompile(`(?i)\bhttps?://[^\s<>"]+`)
+
+type Config struct {
+	// Window is how far back earlier chirps count towards every signal.
+	Window time.Duration
+	// Similarity is the share of shingles two bodies must have in common,
+	// from 0 to 1, to count as near-duplicates.
+	Similarity float64
+
+	// A new account is one younger than NewAccountAge. Posts it makes in the
+	// window beyond NewAccountPostLimit each add to the score.
+	NewAccountAge       time.Duration
+	NewAccountPostLimit int
+	// Earlier chirps in the window sharing a link with this one, beyond
+	// LinkBurstLimit, each add to the score.
+	LinkBurstLimit int
+
+	// The score at which each action kicks in. A threshold of zero or less
+	// turns that action off.
+	ThrottleScore int
+	HoldScore     int
+	RejectScore   int
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Window: time.Hour,
+		Similarity: 0.5,
+		NewAccountAge: 24 * time.Hour,
+		NewAccountPostLimit: 10,
+		LinkBurstLimit: 5,
+		ThrottleScore: 4,
+		HoldScore: 6,
+		RejectScore: 10,
+	}
+}
+
+// Fingerprint is what is kept about a chirp to compare later ones against.
+type Fingerprint struct {
+	// Hash is a digest of the normalized body, equal for bodies that differ
+	// only in case, punctuation and spacing.
+	Hash string
+	// Shingles are hashes of every run of shingleSize words, for spotting
+	// bodies that are nearly but not quite the same.
+	Shingles []int64
+	// Links are the http and https URLs in the body, normalized.
+	Links []string
+	// Words is how many words the body has, not counting links.
+	Words int
+}
+
+func NewFingerprint(body string) Fingerprint {
+	links := extractLinks(body)
+	words := normalizedWords(linkPattern.ReplaceAllString(body, " "))
+
+	hash := sha256.Sum256([]byte(strings.Join(words, " ") + "\n" + strings.Join(links, "\n")))
+	return Fingerprint{
+		Hash: hex.EncodeToString(hash[:]),
+		Shingles: shingles(words, links),
+		Links: links,
+		Words: len(words),
+	}
+}
+
+// distinctive reports whether a body says enough that other accounts
+// posting it too is suspicious. Plenty of people reply "congrats!" or "gm"
+// within the same hour; the same three-word run or link is another matter.
+func (f Fingerprint) distinctive() bool {
+	return f.Words >= shingleSize || len(f.Links) > 0
+}
+
+// Earlier is a recent chirp that may be a duplicate of the one being
+// checked.
+type Earlier struct {
+	SameAuthor bool
+	AuthorID   string
+	Hash       string
+	Shingles   []int64
+}
+
+// Signals are the facts about a new chirp and its author that go into its
+// score.
+type Signals struct {
+	Fingerprint Fingerprint
+	// Candidates are recent chirps that share at least the hash or a
+	// shingle with this one.
+	Candidates []Earlier
+	// LinkPosts is how many recent chirps, from anyone, share a link with
+	// this one.
+	LinkPosts int
+	// RecentPosts is how many chirps the author has posted in the window.
+	RecentPosts int
+	AccountAge  time.Duration
+}
+
+type Verdict struct {
+	Action  string
+	Score   int
+	Reasons []string
+}
+
+// Score weighs the signals and decides what to do with the chirp. Each
+// near-duplicate the author has already posted counts 2, as does each other
+// account that has posted one, since copy-paste campaigns across accounts
+// are the pattern a hard ban doesn't stop; other accounts only count when
+// the body is distinctive enough for that to mean something. Link bursts and
+// floods from new accounts count 1 for every chirp over their limit.
+func (cfg Config) Score(signals Signals) Verdict {
+	verdict := Verdict{Action: ActionAllow, Reasons: []string{}}
+
+	ownDuplicates := 0
+	otherAuthors := map[string]bool{}
+	for _, earlier := range signals.Candidates {
+		if earlier.Hash != signals.Fingerprint.Hash && Similarity(earlier.Shingles, signals.Fingerprint.Shingles) < cfg.Similarity {
+			continue
+		}
+		if earlier.SameAuthor {
+			ownDuplicates++
+		} else {
+			otherAuthors[earlier.AuthorID] = true
+		}
+	}
+	if ownDuplicates > 0 {
+		verdict.Score += 2 * ownDuplicates
+		verdict.Reasons = append(verdict.Reasons, "repeats your recent chirps")
+	}
+	if len(otherAuthors) > 0 && signals.Fingerprint.distinctive() {
+		verdict.Score += 2 * len(otherAuthors)
+		verdict.Reasons = append(verdict.Reasons, "matches recent chirps from other accounts")
+	}
+
+	if len(signals.Fingerprint.Links) > 0 && signals.LinkPosts > cfg.LinkBurstLimit {
+		verdict.Score += signals.LinkPosts - cfg.LinkBurstLimit
+		verdict.Reasons = append(verdict.Reasons, "links to something posted many times recently")
+	}
+
+	if signals.AccountAge < cfg.NewAccountAge && signals.RecentPosts > cfg.NewAccountPostLimit {
+		verdict.Score += signals.RecentPosts - cfg.NewAccountPostLimit
+		verdict.Reasons = append(verdict.Reasons, "posting too quickly for a new account")
+	}
+
+	switch {
+	case cfg.RejectScore > 0 && verdict.Score >= cfg.RejectScore:
+		verdict.Action = ActionReject
+	case cfg.HoldScore > 0 && verdict.Score >= cfg.HoldScore:
+		verdict.Action = ActionHold
+	case cfg.ThrottleScore > 0 && verdict.Score >= cfg.ThrottleScore:
+		verdict.Action = ActionThrottle
+	}
+
+	return verdict
+}
+
+// Similarity is the Jaccard index of two sets of shingles: how many they
+// share out of how many there are between them.
+func Similarity(a, b []int64) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	set := make(map[int64]bool, len(a))
+	for _, shingle := range a {
+		set[shingle] = true
+	}
+
+	shared := 0
+	union := len(set)
+	seen := map[int64]bool{}
+	for _, shingle := range b {
+		if seen[shingle] {
+			continue
+		}
+		seen[shingle] = true
+		if set[shingle] {
+			shared++
+		} else {
+			union++
+		}
+	}
+
+	return float64(shared) / float64(union)
+}
+
+func normalizedWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// shingles hashes every run of shingleSize words, and each link as a
+// shingle of its own. Bodies shorter than a shingle are hashed whole.
+func shingles(words []string, links []string) []int64 {
+	seen := map[int64]bool{}
+	result := []int64{}
+	add := func(text string) {
+		h := fnv.New64a()
+		h.Write([]byte(text))
+		shingle := int64(h.Sum64())
+		if !seen[shingle] {
+			seen[shingle] = true
+			result = append(result, shingle)
+		}
+	}
+
+	if len(words) > 0 && len(words) < shingleSize {
+		add(strings.Join(words, " "))
+	}
+	for i := 0; i+shingleSize <= len(words); i++ {
+		add(strings.Join(words[i:i+shingleSize], " "))
+	}
+	for _, link := range links {
+		add(link)
+	}
+
+	return result
+}
+
+// extractLinks returns the distinct links in text with their scheme and
+// host lowercased and any fragment or trailing punctuation dropped, so
+// trivially different spellings of a link count as the same one.
+func extractLinks(text string) []string {
+	seen := map[string]bool{}
+	links := []string{}
+	for _, raw := range linkPattern.FindAllString(text, -1) {
+		raw = strings.TrimRight(raw, ".,;:!?)]}'")
+		u, err := url.Parse(raw)
+		if err != nil || u.Host == "" {
+			continue
+		}
+		u.Scheme = strings.ToLower(u.Scheme)
+		u.Host = strings.ToLower(u.Host)
+		u.Fragment = ""
+		u.Path = strings.TrimSuffix(u.Path, "/")
+
+		link := u.String()
+		if !seen[link] {
+			seen[link] = true
+			links = append(links, link)
+		}
+	}
+	return links
+}
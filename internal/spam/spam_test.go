@@ -0,0 +1,176 @@
+package spam
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestNewFingerprint(t *testing.T) {
+	tests := []struct {
+		name          string
+		a             string
+		b             string
+		expectSame    bool
+		minSimilarity float64
+		maxSimilarity float64
+	}{
+		{
+			name: "Case, punctuation and spacing are ignored",
+			a: "Buy cheap watches today!",
+			b: "buy   CHEAP watches... today",
+			expectSame: true,
+			minSimilarity: 1,
+			maxSimilarity: 1,
+		},
+		{
+			name: "One changed word is still close",
+			a: "get the best deals on shoes and bags at our store right now",
+			b: "get the best deals on shoes and hats at our store right now",
+			minSimilarity: 0.5,
+			maxSimilarity: 0.9,
+		},
+		{
+			name: "Different chirps aren't",
+			a: "the weather is lovely this morning",
+			b: "just finished reading a great book about birds",
+			maxSimilarity: 0,
+		},
+		{
+			name: "Links are compared after normalizing",
+			a: "check https://Example.com/deal/ now",
+			b: "check https://example.com/deal#top now",
+			expectSame: true,
+			minSimilarity: 1,
+			maxSimilarity: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewFingerprint(tt.a)
+			b := NewFingerprint(tt.b)
+			if (a.Hash == b.Hash) != tt.expectSame {
+				t.Errorf("expected same hash %v, got %v", tt.expectSame, a.Hash == b.Hash)
+			}
+			similarity := Similarity(a.Shingles, b.Shingles)
+			if similarity < tt.minSimilarity || similarity > tt.maxSimilarity {
+				t.Errorf("expected similarity between %v and %v, got %v", tt.minSimilarity, tt.maxSimilarity, similarity)
+			}
+		})
+	}
+}
+
+func TestExtractLinks(t *testing.T) {
+	links := extractLinks("see https://Example.com/a, http://x.io/b). and https://example.com/a/ again")
+	expected := []string{"https://example.com/a", "http://x.io/b"}
+	if !slices.Equal(links, expected) {
+		t.Errorf("expected links %v, got %v", expected, links)
+	}
+}
+
+func TestScore(t *testing.T) {
+	cfg := DefaultConfig()
+	body := NewFingerprint("win a free phone at https://spam.example")
+	duplicate := func(sameAuthor bool, authorID string) Earlier {
+		return Earlier{SameAuthor: sameAuthor, AuthorID: authorID, Hash: body.Hash, Shingles: body.Shingles}
+	}
+	unrelated := NewFingerprint("a completely different chirp about lunch")
+	short := NewFingerprint("Congrats!")
+	shortDuplicate := func(sameAuthor bool, authorID string) Earlier {
+		return Earlier{SameAuthor: sameAuthor, AuthorID: authorID, Hash: short.Hash, Shingles: short.Shingles}
+	}
+
+	tests := []struct {
+		name           string
+		signals        Signals
+		expectedAction string
+		expectedScore  int
+	}{
+		{
+			name: "Nothing suspicious",
+			signals: Signals{Fingerprint: body, AccountAge: 30 * 24 * time.Hour},
+			expectedAction: ActionAllow,
+		},
+		{
+			name: "Unrelated candidates don't count",
+			signals: Signals{
+				Fingerprint: body,
+				Candidates: []Earlier{{SameAuthor: true, AuthorID: "a", Hash: unrelated.Hash, Shingles: unrelated.Shingles}},
+				AccountAge: 30 * 24 * time.Hour,
+			},
+			expectedAction: ActionAllow,
+		},
+		{
+			name: "Repeating yourself twice is throttled",
+			signals: Signals{
+				Fingerprint: body,
+				Candidates: []Earlier{duplicate(true, "a"), duplicate(true, "a")},
+				AccountAge: 30 * 24 * time.Hour,
+			},
+			expectedAction: ActionThrottle,
+			expectedScore: 4,
+		},
+		{
+			name: "The same text from several accounts is held",
+			signals: Signals{
+				Fingerprint: body,
+				Candidates: []Earlier{duplicate(false, "b"), duplicate(false, "c"), duplicate(false, "c"), duplicate(false, "d")},
+				AccountAge: 30 * 24 * time.Hour,
+			},
+			expectedAction: ActionHold,
+			expectedScore: 6,
+		},
+		{
+			name: "Common short replies from other accounts are allowed",
+			signals: Signals{
+				Fingerprint: short,
+				Candidates: []Earlier{shortDuplicate(false, "b"), shortDuplicate(false, "c"), shortDuplicate(false, "d")},
+				AccountAge: 30 * 24 * time.Hour,
+			},
+			expectedAction: ActionAllow,
+		},
+		{
+			name: "Repeating a short reply yourself still counts",
+			signals: Signals{
+				Fingerprint: short,
+				Candidates: []Earlier{shortDuplicate(true, "a"), shortDuplicate(true, "a")},
+				AccountAge: 30 * 24 * time.Hour,
+			},
+			expectedAction: ActionThrottle,
+			expectedScore: 4,
+		},
+		{
+			name: "A new account flooding a link is rejected",
+			signals: Signals{
+				Fingerprint: body,
+				LinkPosts: 10,
+				RecentPosts: 15,
+				AccountAge: time.Hour,
+			},
+			expectedAction: ActionReject,
+			expectedScore: 10,
+		},
+		{
+			name: "Older accounts can post quickly",
+			signals: Signals{
+				Fingerprint: body,
+				RecentPosts: 30,
+				AccountAge: 30 * 24 * time.Hour,
+			},
+			expectedAction: ActionAllow,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict := cfg.Score(tt.signals)
+			if verdict.Action != tt.expectedAction {
+				t.Errorf("expected action %s, got %s (%v)", tt.expectedAction, verdict.Action, verdict.Reasons)
+			}
+			if verdict.Score != tt.expectedScore {
+				t.Errorf("expected score %d, got %d", tt.expectedScore, verdict.Score)
+			}
+		})
+	}
+}
@@ -1,23 +1,40 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"sync/atomic"
+	"time"
 
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
+	"github.com/philipreese/chirpy-go/internal/blobstore"
 	"github.com/philipreese/chirpy-go/internal/database"
+	"github.com/philipreese/chirpy-go/internal/moderation"
+	"github.com/philipreese/chirpy-go/internal/ratelimit"
+	"github.com/philipreese/chirpy-go/internal/spam"
 )
 
 type apiConfig struct {
-	fileserverHits atomic.Int32
-	db             *database.Queries
-	platform       string
-	tokenSecret    string
-	polkaKey       string
+	fileserverHits  atomic.Int32
+	db              *database.Queries
+	dbConn          *sql.DB
+	platform        string
+	tokenSecret     string
+	polkaKey        string
+	chirpEditWindow time.Duration
+	blobs           blobstore.BlobStore
+	moderation      *moderation.Engine
+	spam            spam.Config
+	rateLimitStore  ratelimit.Store
+	limiter         *ratelimit.Limiter
+	trustProxy      bool
+	trashRetention  time.Duration
 }
 
 func main() {
@@ -29,24 +46,104 @@ func main() {
 	mux := http.NewServeMux()
 	mux.Handle("/app/", apiCfg.middlewareMetricsInc(http.StripPrefix("/app", http.FileServer(http.Dir(filePathRoot)))))
 	
+	if localBlobs, ok := apiCfg.blobs.(*blobstore.LocalStore); ok {
+		mux.Handle("GET /media/", http.StripPrefix("/media", apiCfg.middlewareMediaAccess(localBlobs.Handler())))
+	}
+
 	mux.HandleFunc("GET /api/healthz", handlerReadiness)
 
-	mux.HandleFunc("POST /api/login", apiCfg.handlerLogin)
-	mux.HandleFunc("POST /api/refresh", apiCfg.handlerRefresh)
+	mux.Handle("POST /api/login", apiCfg.rateLimited("login", 5, time.Minute, keyByIP, apiCfg.handlerLogin))
+	mux.Handle("POST /api/refresh", apiCfg.rateLimited("refresh", 30, time.Minute, keyByIP, apiCfg.handlerRefresh))
 	mux.HandleFunc("POST /api/revoke", apiCfg.handlerRevoke)
 
-	mux.HandleFunc("POST /api/polka/webhooks", apiCfg.handlerWebhook)
+	mux.Handle("POST /api/polka/webhooks", apiCfg.rateLimited("webhooks", 60, time.Minute, keyByIP, apiCfg.handlerWebhook))
 
-	mux.HandleFunc("POST /api/users", apiCfg.handlerCreateUser)
+	mux.Handle("POST /api/users", apiCfg.rateLimited("signup", 5, time.Hour, keyByIP, apiCfg.handlerCreateUser))
 	mux.HandleFunc("PUT /api/users", apiCfg.handlerUpdateUser)
+	mux.HandleFunc("GET /api/users/{userID}/likes", apiCfg.handlerGetUserLikes)
+	mux.HandleFunc("GET /api/users/{handle}", apiCfg.handlerGetProfile)
+	mux.HandleFunc("GET /api/users/{handle}/chirps", apiCfg.handlerGetProfileChirps)
+	mux.Handle("POST /api/users/{userID}/follow", apiCfg.rateLimited("interactions", 120, time.Minute, keyByUser, apiCfg.handlerFollowUser))
+	mux.HandleFunc("DELETE /api/users/{userID}/follow", apiCfg.handlerUnfollowUser)
+	mux.HandleFunc("GET /api/users/{userID}/followers", apiCfg.handlerGetFollowers)
+	mux.HandleFunc("GET /api/users/{userID}/following", apiCfg.handlerGetFollowing)
+	mux.HandleFunc("POST /api/users/{userID}/block", apiCfg.handlerBlockUser)
+	mux.HandleFunc("DELETE /api/users/{userID}/block", apiCfg.handlerUnblockUser)
+	mux.HandleFunc("POST /api/users/{userID}/mute", apiCfg.handlerMuteUser)
+	mux.HandleFunc("DELETE /api/users/{userID}/mute", apiCfg.handlerUnmuteUser)
+	mux.HandleFunc("GET /api/blocks", apiCfg.handlerGetBlockedUsers)
+	mux.HandleFunc("GET /api/mutes", apiCfg.handlerGetMutedUsers)
+
+	mux.HandleFunc("GET /api/timeline", apiCfg.handlerGetTimeline)
+	mux.HandleFunc("GET /api/notifications", apiCfg.handlerGetNotifications)
+	mux.HandleFunc("GET /api/notifications/unread_count", apiCfg.handlerGetUnreadNotificationCount)
+	mux.HandleFunc("POST /api/notifications/read", apiCfg.handlerMarkAllNotificationsRead)
+	mux.HandleFunc("POST /api/notifications/{notificationID}/read", apiCfg.handlerMarkNotificationRead)
+
+	mux.Handle("POST /api/conversations", apiCfg.rateLimited("messages", 60, time.Minute, keyByUser, apiCfg.handlerCreateConversation))
+	mux.HandleFunc("GET /api/conversations", apiCfg.handlerGetConversations)
+	mux.HandleFunc("DELETE /api/conversations/{conversationID}", apiCfg.handlerDeleteConversation)
+	mux.HandleFunc("GET /api/conversations/{conversationID}/messages", apiCfg.handlerGetMessages)
+	mux.Handle("POST /api/conversations/{conversationID}/messages", apiCfg.rateLimited("messages", 60, time.Minute, keyByUser, apiCfg.handlerSendMessage))
+	mux.HandleFunc("POST /api/conversations/{conversationID}/read", apiCfg.handlerMarkConversationRead)
 
 	mux.HandleFunc("GET /api/chirps", apiCfg.handlerGetChirps)
+	mux.Handle("GET /api/chirps/search", apiCfg.rateLimited("search", 60, time.Minute, keyByUser, apiCfg.handlerSearchChirps))
 	mux.HandleFunc("GET /api/chirps/{chirpID}", apiCfg.handlerGetChirpByID)
-	mux.HandleFunc("POST /api/chirps", apiCfg.handlerCreateChirp)
+	mux.Handle("POST /api/chirps", apiCfg.rateLimited("chirps", 30, 10*time.Minute, keyByUser, apiCfg.handlerCreateChirp))
+	mux.Handle("POST /api/media", apiCfg.rateLimited("media", 20, 10*time.Minute, keyByUser, apiCfg.handlerUploadMedia))
+	mux.HandleFunc("POST /api/drafts", apiCfg.handlerCreateDraft)
+	mux.HandleFunc("GET /api/drafts", apiCfg.handlerGetDrafts)
+	mux.HandleFunc("GET /api/drafts/{draftID}", apiCfg.handlerGetDraft)
+	mux.HandleFunc("PUT /api/drafts/{draftID}", apiCfg.handlerSaveDraft)
+	mux.HandleFunc("DELETE /api/drafts/{draftID}", apiCfg.handlerDeleteDraft)
+	mux.Handle("POST /api/drafts/{draftID}/publish", apiCfg.rateLimited("chirps", 30, 10*time.Minute, keyByUser, apiCfg.handlerPublishDraft))
+	mux.HandleFunc("GET /api/scheduled_chirps", apiCfg.handlerGetScheduledChirps)
+	mux.HandleFunc("PATCH /api/scheduled_chirps/{scheduledChirpID}", apiCfg.handlerRescheduleChirp)
+	mux.HandleFunc("DELETE /api/scheduled_chirps/{scheduledChirpID}", apiCfg.handlerCancelScheduledChirp)
+	mux.HandleFunc("PATCH /api/chirps/{chirpID}", apiCfg.handlerUpdateChirp)
 	mux.HandleFunc("DELETE /api/chirps/{chirpID}", apiCfg.handlerDeleteChirp)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/restore", apiCfg.handlerRestoreChirp)
+	mux.HandleFunc("GET /api/trash", apiCfg.handlerGetTrash)
+	mux.HandleFunc("GET /api/chirps/{chirpID}/revisions", apiCfg.handlerGetChirpRevisions)
+	mux.HandleFunc("GET /api/chirps/{chirpID}/thread", apiCfg.handlerGetChirpThread)
+	mux.Handle("POST /api/chirps/{chirpID}/rechirp", apiCfg.rateLimited("interactions", 120, time.Minute, keyByUser, apiCfg.handlerRechirp))
+	mux.HandleFunc("DELETE /api/chirps/{chirpID}/rechirp", apiCfg.handlerUndoRechirp)
+	mux.Handle("PUT /api/chirps/{chirpID}/like", apiCfg.rateLimited("interactions", 120, time.Minute, keyByUser, apiCfg.handlerLikeChirp))
+	mux.HandleFunc("DELETE /api/chirps/{chirpID}/like", apiCfg.handlerUnlikeChirp)
+	mux.HandleFunc("GET /api/chirps/{chirpID}/likes", apiCfg.handlerGetChirpLikes)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/vote", apiCfg.handlerVotePoll)
+
+	mux.Handle("POST /api/reports", apiCfg.rateLimited("reports", 20, time.Hour, keyByUser, apiCfg.handlerCreateReport))
+
+	mux.HandleFunc("GET /api/tags/trending", apiCfg.handlerGetTrendingTags)
+	mux.HandleFunc("GET /api/tags/{tag}/chirps", apiCfg.handlerGetTagChirps)
 	
 	mux.HandleFunc("POST /admin/reset", apiCfg.handlerReset)
 	mux.HandleFunc("GET /admin/metrics", apiCfg.handlerMetrics)
+	mux.HandleFunc("PUT /admin/chirps/{chirpID}/sensitive", apiCfg.handlerSetChirpSensitive)
+	mux.HandleFunc("GET /admin/chirps/deleted", apiCfg.handlerGetDeletedChirps)
+	mux.HandleFunc("GET /admin/moderation/rules", apiCfg.handlerGetModerationRules)
+	mux.HandleFunc("POST /admin/moderation/rules", apiCfg.handlerCreateModerationRule)
+	mux.HandleFunc("PUT /admin/moderation/rules/{ruleID}", apiCfg.handlerUpdateModerationRule)
+	mux.HandleFunc("DELETE /admin/moderation/rules/{ruleID}", apiCfg.handlerDeleteModerationRule)
+	mux.HandleFunc("GET /admin/moderation/held", apiCfg.handlerGetHeldChirps)
+	mux.HandleFunc("POST /admin/moderation/held/{chirpID}/approve", apiCfg.handlerApproveHeldChirp)
+	mux.HandleFunc("POST /admin/moderation/held/{chirpID}/reject", apiCfg.handlerRejectHeldChirp)
+	mux.HandleFunc("PUT /admin/users/{userID}/suspension", apiCfg.handlerSuspendUser)
+	mux.HandleFunc("DELETE /admin/users/{userID}/suspension", apiCfg.handlerUnsuspendUser)
+	mux.HandleFunc("PUT /admin/users/{userID}/shadow_ban", apiCfg.handlerShadowBanUser)
+	mux.HandleFunc("DELETE /admin/users/{userID}/shadow_ban", apiCfg.handlerLiftShadowBan)
+	mux.HandleFunc("GET /admin/reports", apiCfg.handlerGetReportCases)
+	mux.HandleFunc("GET /admin/reports/{caseID}", apiCfg.handlerGetReportCase)
+	mux.HandleFunc("POST /admin/reports/{caseID}/claim", apiCfg.handlerClaimReportCase)
+	mux.HandleFunc("DELETE /admin/reports/{caseID}/claim", apiCfg.handlerReleaseReportCase)
+	mux.HandleFunc("POST /admin/reports/{caseID}/resolve", apiCfg.handlerResolveReportCase)
+
+	go apiCfg.publishScheduledChirps(context.Background(), scheduledChirpPollInterval)
+	go apiCfg.reloadModerationRules(context.Background(), moderationRulesReloadInterval)
+	go apiCfg.sweepRateLimits(context.Background(), rateLimitSweepInterval)
+	go apiCfg.purgeTrash(context.Background(), trashPurgeInterval)
 
 	server := &http.Server{
 		Handler: mux,
@@ -89,13 +186,123 @@ func loadConfig() (cfg *apiConfig) {
 		return nil
 	}
 
+	chirpEditWindow := 15 * time.Minute
+	if editWindow := os.Getenv("CHIRP_EDIT_WINDOW"); editWindow != "" {
+		chirpEditWindow, err = time.ParseDuration(editWindow)
+		if err != nil {
+			log.Fatalf("CHIRP_EDIT_WINDOW is not a valid duration: %v", err)
+			return nil
+		}
+	}
+
+	trashRetention := 30 * 24 * time.Hour
+	if retention := os.Getenv("CHIRP_TRASH_RETENTION"); retention != "" {
+		trashRetention, err = time.ParseDuration(retention)
+		if err != nil || trashRetention <= 0 {
+			log.Fatalf("CHIRP_TRASH_RETENTION is not a valid positive duration: %q", retention)
+			return nil
+		}
+	}
+
+	mediaDir := os.Getenv("MEDIA_DIR")
+	if mediaDir == "" {
+		mediaDir = "./uploads"
+	}
+	mediaBaseURL := os.Getenv("MEDIA_BASE_URL")
+	if mediaBaseURL == "" {
+		mediaBaseURL = "/media"
+	}
+	blobs, err := blobstore.NewLocalStore(mediaDir, mediaBaseURL)
+	if err != nil {
+		log.Fatalf("Failed to set up media storage: %v", err)
+		return nil
+	}
+
+	var rateLimitStore ratelimit.Store
+	switch store := os.Getenv("RATE_LIMIT_STORE"); store {
+	case "", "memory":
+		rateLimitStore = ratelimit.NewMemoryStore()
+	case "postgres":
+		rateLimitStore = ratelimit.NewPostgresStore(db)
+	default:
+		log.Fatalf("RATE_LIMIT_STORE must be memory or postgres, not %q", store)
+		return nil
+	}
+
+	spamConfig, err := loadSpamConfig()
+	if err != nil {
+		log.Fatalf("Invalid spam settings: %v", err)
+		return nil
+	}
+
 	apiCfg := apiConfig{
 		fileserverHits: atomic.Int32{},
 		db: database.New(db),
+		dbConn: db,
 		platform: platform,
 		tokenSecret: tokenSecret,
 		polkaKey: polkaKey,
+		chirpEditWindow: chirpEditWindow,
+		blobs: blobs,
+		moderation: moderation.NewEngine(),
+		spam: spamConfig,
+		rateLimitStore: rateLimitStore,
+		limiter: ratelimit.NewLimiter(rateLimitStore),
+		trustProxy: os.Getenv("RATE_LIMIT_TRUST_PROXY") == "true",
+		trashRetention: trashRetention,
+	}
+
+	if err := apiCfg.loadModerationRules(context.Background()); err != nil {
+		log.Fatalf("Failed to load moderation rules: %v", err)
+		return nil
 	}
 
 	return &apiCfg
-}
\ No newline at end of file
+}
+
+// loadSpamConfig starts from the spam defaults and overrides whichever of
+// them are set in the environment.
+func loadSpamConfig() (spam.Config, error) {
+	cfg := spam.DefaultConfig()
+
+	durations := map[string]*time.Duration{
+		"SPAM_WINDOW": &cfg.Window,
+		"SPAM_NEW_ACCOUNT_AGE": &cfg.NewAccountAge,
+	}
+	for name, value := range durations {
+		if setting := os.Getenv(name); setting != "" {
+			parsed, err := time.ParseDuration(setting)
+			if err != nil || parsed <= 0 {
+				return spam.Config{}, fmt.Errorf("%s is not a valid positive duration: %q", name, setting)
+			}
+			*value = parsed
+		}
+	}
+
+	ints := map[string]*int{
+		"SPAM_NEW_ACCOUNT_POST_LIMIT": &cfg.NewAccountPostLimit,
+		"SPAM_LINK_BURST_LIMIT": &cfg.LinkBurstLimit,
+		"SPAM_THROTTLE_SCORE": &cfg.ThrottleScore,
+		"SPAM_HOLD_SCORE": &cfg.HoldScore,
+		"SPAM_REJECT_SCORE": &cfg.RejectScore,
+	}
+	for name, value := range ints {
+		if setting := os.Getenv(name); setting != "" {
+			parsed, err := strconv.Atoi(setting)
+			if err != nil {
+				return spam.Config{}, fmt.Errorf("%s is not a valid number: %q", name, setting)
+			}
+			*value = parsed
+		}
+	}
+
+	if setting := os.Getenv("SPAM_SIMILARITY"); setting != "" {
+		similarity, err := strconv.ParseFloat(setting, 64)
+		if err != nil || similarity <= 0 || similarity > 1 {
+			return spam.Config{}, fmt.Errorf("SPAM_SIMILARITY must be a number above 0 and at most 1: %q", setting)
+		}
+		cfg.Similarity = similarity
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/philipreese/chirpy-go/internal/database"
+)
+
+// Notification types. The table stores the type as plain text next to a JSON
+// payload, so a new kind of event only needs a constant and a payload here.
+const (
+	NotificationChirpReply   = "chirp_reply"
+	NotificationChirpyRed    = "chirpy_red_upgraded"
+	NotificationChirpRemoved = "chirp_removed"
+	NotificationNewLogin     = "new_login"
+
+	NotificationScheduledChirpFailed = "scheduled_chirp_failed"
+	NotificationModerationWarning    = "moderation_warning"
+)
+
+type chirpReplyPayload struct {
+	ChirpID uuid.UUID `json:"chirp_id"`
+	ReplyID uuid.UUID `json:"reply_id"`
+	UserID  uuid.UUID `json:"user_id"`
+}
+
+type chirpyRedPayload struct{}
+
+type chirpRemovedPayload struct {
+	ChirpID uuid.UUID `json:"chirp_id"`
+	Reason  string    `json:"reason"`
+}
+
+type scheduledChirpFailedPayload struct {
+	ScheduledChirpID uuid.UUID `json:"scheduled_chirp_id"`
+	Body             string    `json:"body"`
+	Reason           string    `json:"reason"`
+}
+
+type moderationWarningPayload struct {
+	ChirpID uuid.NullUUID `json:"chirp_id"`
+	Reason  string        `json:"reason"`
+}
+
+type newLoginPayload struct {
+	UserAgent string `json:"user_agent"`
+	IPAddress string `json:"ip_address"`
+}
+
+func notify(ctx context.Context, q *database.Queries, userID uuid.UUID, notificationType string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.CreateNotification(ctx, database.CreateNotificationParams{
+		UserID: userID,
+		Type: notificationType,
+		Payload: data,
+	})
+	return err
+}
+
+// notifyReply tells the author of parent about reply, unless they are
+// replying to themselves or the reply is private, held for review or from
+// a shadow-banned user and they can't see it.
+func notifyReply(ctx context.Context, q *database.Queries, parent, reply database.Chirp) error {
+	if parent.UserID == reply.UserID || reply.Visibility == VisibilityPrivate || reply.HeldForReview {
+		return nil
+	}
+
+	author, err := q.GetUserByID(ctx, reply.UserID)
+	if err != nil {
+		return err
+	}
+	if author.ShadowBanned {
+		return nil
+	}
+
+	return notify(ctx, q, parent.UserID, NotificationChirpReply, chirpReplyPayload{
+		ChirpID: parent.ID,
+		ReplyID: reply.ID,
+		UserID: reply.UserID,
+	})
+}
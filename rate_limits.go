@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/philipreese/chirpy-go/internal/auth"
+	"github.com/philipreese/chirpy-go/internal/ratelimit"
+)
+
+const rateLimitSweepInterval = time.Minute
+
+// What a rate limit counts requests against.
+const (
+	keyByIP = iota
+	// keyByUser counts signed-in requests against the user, wherever they
+	// come from, and the rest against their IP address.
+	keyByUser
+)
+
+// rateLimited wraps a handler in a rate limit of limit requests per period.
+// Routes that share a name share a limit.
+func (cfg *apiConfig) rateLimited(name string, limit int, period time.Duration, key int, handler http.HandlerFunc) http.Handler {
+	policy := ratelimit.Policy{Name: name, Limit: limit, Period: period}
+	switch key {
+	case keyByIP:
+		policy.Key = ratelimit.ByIP(cfg.trustProxy)
+	case keyByUser:
+		policy.Key = ratelimit.FirstOf(cfg.rateLimitUserKey, ratelimit.ByIP(cfg.trustProxy))
+	}
+	return cfg.limiter.Limit(policy, handler)
+}
+
+// rateLimitUserKey keys a request by the user its access token belongs to.
+// Requests without a valid token get no key.
+func (cfg *apiConfig) rateLimitUserKey(req *http.Request) string {
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		return ""
+	}
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		return ""
+	}
+	return "user:" + userID.String()
+}
+
+// sweepRateLimits drops rate limit buckets that have filled back up every
+// interval until ctx is cancelled.
+func (cfg *apiConfig) sweepRateLimits(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := cfg.rateLimitStore.Sweep(ctx, time.Now().UTC()); err != nil {
+			log.Printf("Couldn't sweep rate limits: %v", err)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/philipreese/chirpy-go/internal/auth"
+	"github.com/philipreese/chirpy-go/internal/database"
+)
+
+// User roles. There is no endpoint for handing out roles; they are set
+// directly in the users table.
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// requireRole authenticates a request and checks that the caller holds one
+// of the given roles. It writes the error response itself, so callers
+// should just return when ok is false.
+func (cfg *apiConfig) requireRole(writer http.ResponseWriter, req *http.Request, roles ...string) (user database.User, ok bool) {
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't get bearer token: " + err.Error())
+		return database.User{}, false
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't validate JWT: " + err.Error())
+		return database.User{}, false
+	}
+
+	user, err = cfg.db.GetUserByID(req.Context(), userID)
+	if err != nil {
+		respondWithError(writer, http.StatusUnauthorized, "Couldn't find user: " + err.Error())
+		return database.User{}, false
+	}
+
+	if !slices.Contains(roles, user.Role) {
+		respondWithError(writer, http.StatusForbidden, "You don't have permission to do that")
+		return database.User{}, false
+	}
+
+	return user, true
+}
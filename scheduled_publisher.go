@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/philipreese/chirpy-go/internal/database"
+	"github.com/philipreese/chirpy-go/internal/spam"
+)
+
+const (
+	scheduledChirpPollInterval = 10 * time.Second
+
+	// a scheduled chirp that fails to publish is set aside for
+	// scheduledChirpRetryDelay so the ones behind it still go out, and
+	// dropped after maxScheduledChirpAttempts
+	scheduledChirpRetryDelay  = time.Minute
+	maxScheduledChirpAttempts = 5
+)
+
+// publishScheduledChirps publishes due scheduled chirps every interval until
+// ctx is cancelled. Every server runs one of these; claiming a chirp locks
+// its row and skips rows other publishers hold, and the chirp is created
+// and the scheduled row deleted in the same transaction, so each scheduled
+// chirp is published exactly once however many instances are running.
+func (cfg *apiConfig) publishScheduledChirps(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			published, err := cfg.publishNextScheduledChirp(ctx)
+			if err != nil {
+				log.Printf("Couldn't publish scheduled chirp: %v", err)
+				break
+			}
+			if !published {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// publishNextScheduledChirp claims the most overdue scheduled chirp and
+// publishes it, reporting false when there was nothing due. A chirp that
+// can't be published is recorded as having failed and passed over, and
+// false comes back only if even that couldn't be done.
+func (cfg *apiConfig) publishNextScheduledChirp(ctx context.Context) (bool, error) {
+	tx, err := cfg.dbConn.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	scheduled, err := qtx.ClaimDueScheduledChirp(ctx, time.Now().UTC().Add(-scheduledChirpRetryDelay))
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := cfg.publishScheduledChirp(ctx, qtx, scheduled); err != nil {
+		log.Printf("Couldn't publish scheduled chirp %s: %v", scheduled.ID, err)
+		tx.Rollback()
+		if err := cfg.recordScheduledChirpFailure(ctx, scheduled.ID); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	if err := qtx.DeleteScheduledChirp(ctx, scheduled.ID); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// publishScheduledChirp turns a scheduled chirp into a real one. The chirp
+// being replied to or quoted may have gone, or its author may have blocked
+// this one, since it was scheduled; the author is told and nothing is
+// published, leaving any media free to be used again. The same goes for an
+// author who has been suspended. The moderation rules may have changed too,
+// so the chirp is checked against them again, and it's checked for spam
+// against what has been posted since. A chirp that would have been
+// throttled can't be asked to wait, so it's held for review instead.
+func (cfg *apiConfig) publishScheduledChirp(ctx context.Context, q *database.Queries, scheduled database.ScheduledChirp) error {
+	author := uuid.NullUUID{UUID: scheduled.UserID, Valid: true}
+
+	user, err := q.GetUserByID(ctx, scheduled.UserID)
+	if err != nil {
+		return err
+	}
+	if isSuspended(user) {
+		return notifyScheduledChirpFailed(ctx, q, scheduled, "the account is suspended")
+	}
+
+	body, bodyHeld, err := cfg.validateChirpBody(scheduled.Body)
+	if err != nil {
+		return notifyScheduledChirpFailed(ctx, q, scheduled, err.Error())
+	}
+
+	contentWarning, warningHeld, err := cfg.validateContentWarning(scheduled.ContentWarning)
+	if err != nil {
+		return notifyScheduledChirpFailed(ctx, q, scheduled, err.Error())
+	}
+
+	verdict, err := cfg.scoreSpam(ctx, q, scheduled.UserID, body, uuid.NullUUID{})
+	if err != nil {
+		return err
+	}
+	if verdict.Action == spam.ActionReject {
+		return notifyScheduledChirpFailed(ctx, q, scheduled, "it looks like spam")
+	}
+	spamHeld := verdict.Action == spam.ActionHold || verdict.Action == spam.ActionThrottle
+
+	var parent database.Chirp
+	if scheduled.ParentID.Valid {
+		parent, err = q.GetChirpByID(ctx, database.GetChirpByIDParams{ID: scheduled.ParentID.UUID, ViewerID: author})
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && parent.TombstonedAt.Valid) {
+			return notifyScheduledChirpFailed(ctx, q, scheduled, "the chirp being replied to is no longer available")
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if scheduled.QuotedChirpID.Valid {
+		quoted, err := q.GetChirpByID(ctx, database.GetChirpByIDParams{ID: scheduled.QuotedChirpID.UUID, ViewerID: author})
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && quoted.TombstonedAt.Valid) {
+			return notifyScheduledChirpFailed(ctx, q, scheduled, "the chirp being quoted is no longer available")
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	dbChirp, err := insertChirp(ctx, q, database.CreateChirpParams{
+		Body: body,
+		UserID: scheduled.UserID,
+		ParentID: scheduled.ParentID,
+		QuotedChirpID: scheduled.QuotedChirpID,
+		Visibility: scheduled.Visibility,
+		ContentWarning: contentWarning,
+		Sensitive: scheduled.Sensitive,
+		HeldForReview: bodyHeld || warningHeld || spamHeld,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = q.AttachScheduledMediaToChirp(ctx, database.AttachScheduledMediaToChirpParams{
+		ChirpID: uuid.NullUUID{UUID: dbChirp.ID, Valid: true},
+		ScheduledChirpID: uuid.NullUUID{UUID: scheduled.ID, Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+
+	if scheduled.ParentID.Valid {
+		return notifyReply(ctx, q, parent, dbChirp)
+	}
+
+	return nil
+}
+
+// recordScheduledChirpFailure counts a failed attempt to publish a scheduled
+// chirp. Once it has failed maxScheduledChirpAttempts times its author is
+// told and it's deleted, leaving any media free to be used again.
+func (cfg *apiConfig) recordScheduledChirpFailure(ctx context.Context, id uuid.UUID) error {
+	tx, err := cfg.dbConn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	scheduled, err := qtx.RecordScheduledChirpFailure(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if scheduled.FailedAttempts >= maxScheduledChirpAttempts {
+		if err := notifyScheduledChirpFailed(ctx, qtx, scheduled, "it couldn't be published"); err != nil {
+			return err
+		}
+		if err := qtx.DeleteScheduledChirp(ctx, scheduled.ID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func notifyScheduledChirpFailed(ctx context.Context, q *database.Queries, scheduled database.ScheduledChirp, reason string) error {
+	return notify(ctx, q, scheduled.UserID, NotificationScheduledChirpFailed, scheduledChirpFailedPayload{
+		ScheduledChirpID: scheduled.ID,
+		Body: scheduled.Body,
+		Reason: reason,
+	})
+}
@@ -0,0 +1,52 @@
+-- name: BlockUser :exec
+INSERT INTO blocks(blocker_id, blocked_id, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT (blocker_id, blocked_id) DO NOTHING;
+
+-- name: UnblockUser :exec
+DELETE FROM blocks
+WHERE blocker_id = $1
+    AND blocked_id = $2;
+
+-- name: BlockExists :one
+SELECT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocker_id = sqlc.arg('user_id') AND blocked_id = sqlc.arg('other_user_id'))
+        OR (blocker_id = sqlc.arg('other_user_id') AND blocked_id = sqlc.arg('user_id'))
+);
+
+-- name: GetBlockedUsers :many
+SELECT sqlc.embed(users), blocks.created_at AS blocked_at
+FROM blocks
+JOIN users ON users.id = blocks.blocked_id
+WHERE blocks.blocker_id = sqlc.arg('user_id')
+    AND (sqlc.narg('cursor_created_at')::timestamp IS NULL
+        OR (blocks.created_at, blocks.blocked_id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY blocks.created_at DESC, blocks.blocked_id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: MuteUser :exec
+INSERT INTO mutes(muter_id, muted_id, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT (muter_id, muted_id) DO NOTHING;
+
+-- name: UnmuteUser :exec
+DELETE FROM mutes
+WHERE muter_id = $1
+    AND muted_id = $2;
+
+-- name: GetMutedUsers :many
+SELECT sqlc.embed(users), mutes.created_at AS muted_at
+FROM mutes
+JOIN users ON users.id = mutes.muted_id
+WHERE mutes.muter_id = sqlc.arg('user_id')
+    AND (sqlc.narg('cursor_created_at')::timestamp IS NULL
+        OR (mutes.created_at, mutes.muted_id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY mutes.created_at DESC, mutes.muted_id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: LockUserPair :exec
+SELECT id FROM users
+WHERE id IN (sqlc.arg('user_id'), sqlc.arg('other_user_id'))
+ORDER BY id
+FOR UPDATE;
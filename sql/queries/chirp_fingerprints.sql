@@ -0,0 +1,27 @@
+-- name: SaveChirpFingerprint :exec
+INSERT INTO chirp_fingerprints(chirp_id, user_id, created_at, content_hash, shingles, links)
+VALUES ($1, $2, $3, $4, sqlc.arg('shingles')::bigint[], sqlc.arg('links')::text[])
+ON CONFLICT (chirp_id) DO UPDATE
+SET content_hash = EXCLUDED.content_hash,
+    shingles = EXCLUDED.shingles,
+    links = EXCLUDED.links;
+
+-- name: GetSimilarFingerprints :many
+SELECT user_id, content_hash, shingles FROM chirp_fingerprints
+WHERE created_at > sqlc.arg('since')
+AND chirp_id IS DISTINCT FROM sqlc.narg('excluding_chirp_id')
+AND (content_hash = sqlc.arg('content_hash') OR shingles && sqlc.arg('shingles')::bigint[])
+ORDER BY created_at DESC
+LIMIT sqlc.arg('limit');
+
+-- name: CountLinkPosts :one
+SELECT COUNT(*) FROM chirp_fingerprints
+WHERE created_at > sqlc.arg('since')
+AND chirp_id IS DISTINCT FROM sqlc.narg('excluding_chirp_id')
+AND links && sqlc.arg('links')::text[];
+
+-- name: CountUserChirpsSince :one
+SELECT COUNT(*) FROM chirp_fingerprints
+WHERE user_id = sqlc.arg('user_id')
+AND created_at > sqlc.arg('since')
+AND chirp_id IS DISTINCT FROM sqlc.narg('excluding_chirp_id');
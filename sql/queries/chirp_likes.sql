@@ -0,0 +1,48 @@
+-- name: LikeChirp :exec
+INSERT INTO chirp_likes(user_id, chirp_id, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT (user_id, chirp_id) DO NOTHING;
+
+-- name: UnlikeChirp :exec
+DELETE FROM chirp_likes
+WHERE user_id = $1
+    AND chirp_id = $2;
+
+-- name: DeleteChirpLikes :exec
+DELETE FROM chirp_likes
+WHERE chirp_id = $1;
+
+-- name: GetLikeCounts :many
+SELECT chirp_id, COUNT(*) AS like_count
+FROM chirp_likes
+WHERE chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[])
+    AND user_visible_to(user_id, sqlc.narg('viewer_id')::uuid, FALSE)
+GROUP BY chirp_id;
+
+-- name: GetLikedChirpIDs :many
+SELECT chirp_id FROM chirp_likes
+WHERE user_id = sqlc.arg('user_id')
+    AND chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[]);
+
+-- name: GetChirpLikes :many
+SELECT * FROM chirp_likes
+WHERE chirp_id = sqlc.arg('chirp_id')
+    AND user_visible_to(user_id, sqlc.narg('viewer_id')::uuid, TRUE)
+    AND (sqlc.narg('cursor_created_at')::timestamp IS NULL
+        OR (created_at, user_id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY created_at DESC, user_id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: GetLikedChirps :many
+SELECT sqlc.embed(chirps), chirp_likes.created_at AS liked_at
+FROM chirp_likes
+JOIN chirps ON chirps.id = chirp_likes.chirp_id
+WHERE chirp_likes.user_id = sqlc.arg('user_id')
+    AND user_visible_to(chirp_likes.user_id, sqlc.narg('viewer_id')::uuid, TRUE)
+    AND chirps.tombstoned_at IS NULL
+    AND chirps.deleted_at IS NULL
+    AND chirp_visible_to(chirps.id, sqlc.narg('viewer_id')::uuid, TRUE)
+    AND (sqlc.narg('cursor_created_at')::timestamp IS NULL
+        OR (chirp_likes.created_at, chirp_likes.chirp_id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY chirp_likes.created_at DESC, chirp_likes.chirp_id DESC
+LIMIT sqlc.arg('limit');
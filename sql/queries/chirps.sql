@@ -1,21 +1,373 @@
 -- name: CreateChirp :one
-INSERT INTO chirps(id, created_at, updated_at, body, user_id)
-VALUES (gen_random_uuid(), NOW(), NOW(), $1, $2)
+INSERT INTO chirps(id, created_at, updated_at, body, user_id, parent_id, quoted_chirp_id, visibility, content_warning, sensitive, held_for_review)
+VALUES (gen_random_uuid(), NOW(), NOW(), $1, $2, $3, $4, $5, $6, $7, $8)
 RETURNING *;
 
 -- name: GetChirps :many
 SELECT * FROM chirps
-ORDER BY created_at ASC;
+WHERE tombstoned_at IS NULL
+    AND deleted_at IS NULL
+    AND chirp_visible_to(id, sqlc.narg('viewer_id')::uuid, TRUE)
+    AND (sqlc.narg('cursor_created_at')::timestamp IS NULL
+        OR (created_at, id) > (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg('limit');
 
--- name: GetChirpsByUserID :many
+-- name: GetChirpsDesc :many
 SELECT * FROM chirps
-WHERE user_id = $1
-ORDER BY created_at ASC;
+WHERE tombstoned_at IS NULL
+    AND deleted_at IS NULL
+    AND chirp_visible_to(id, sqlc.narg('viewer_id')::uuid, TRUE)
+    AND (sqlc.narg('cursor_created_at')::timestamp IS NULL
+        OR (created_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: GetTimeline :many
+SELECT sqlc.embed(chirps), feed.active_at, feed.rechirped_by
+FROM (
+    (SELECT chirps.id AS chirp_id, chirps.created_at AS active_at, NULL::uuid AS rechirped_by
+    FROM chirps
+    WHERE (chirps.user_id = sqlc.arg('user_id')
+            OR chirps.user_id IN (SELECT followee_id FROM follows WHERE follower_id = sqlc.arg('user_id')))
+        AND NOT EXISTS (SELECT 1 FROM rechirps
+            WHERE rechirps.chirp_id = chirps.id
+                AND (rechirps.user_id = sqlc.arg('user_id')
+                    OR rechirps.user_id IN (SELECT followee_id FROM follows WHERE follower_id = sqlc.arg('user_id')))
+                AND user_visible_to(rechirps.user_id, sqlc.arg('user_id'), TRUE))
+        AND chirps.tombstoned_at IS NULL
+        AND chirps.deleted_at IS NULL
+        AND chirp_visible_to(chirps.id, sqlc.arg('user_id'), TRUE)
+        AND (sqlc.narg('cursor_created_at')::timestamp IS NULL
+            OR (chirps.created_at, chirps.id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+    ORDER BY chirps.created_at DESC, chirps.id DESC
+    LIMIT sqlc.arg('limit'))
+    UNION ALL
+    (SELECT rechirps.chirp_id, rechirps.created_at, rechirps.user_id
+    FROM rechirps
+    JOIN chirps ON chirps.id = rechirps.chirp_id
+    WHERE (rechirps.user_id = sqlc.arg('user_id')
+            OR rechirps.user_id IN (SELECT followee_id FROM follows WHERE follower_id = sqlc.arg('user_id')))
+        AND user_visible_to(rechirps.user_id, sqlc.arg('user_id'), TRUE)
+        AND NOT EXISTS (SELECT 1 FROM rechirps AS later
+            WHERE later.chirp_id = rechirps.chirp_id
+                AND (later.user_id = sqlc.arg('user_id')
+                    OR later.user_id IN (SELECT followee_id FROM follows WHERE follower_id = sqlc.arg('user_id')))
+                AND user_visible_to(later.user_id, sqlc.arg('user_id'), TRUE)
+                AND (later.created_at, later.user_id) > (rechirps.created_at, rechirps.user_id))
+        AND chirps.tombstoned_at IS NULL
+        AND chirps.deleted_at IS NULL
+        AND chirp_visible_to(chirps.id, sqlc.arg('user_id'), TRUE)
+        AND (sqlc.narg('cursor_created_at')::timestamp IS NULL
+            OR (rechirps.created_at, rechirps.chirp_id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+    ORDER BY rechirps.created_at DESC, rechirps.chirp_id DESC
+    LIMIT sqlc.arg('limit'))
+) AS feed
+JOIN chirps ON chirps.id = feed.chirp_id
+ORDER BY feed.active_at DESC, chirps.id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: GetTimelineNewer :many
+SELECT sqlc.embed(chirps), feed.active_at, feed.rechirped_by
+FROM (
+    (SELECT chirps.id AS chirp_id, chirps.created_at AS active_at, NULL::uuid AS rechirped_by
+    FROM chirps
+    WHERE (chirps.user_id = sqlc.arg('user_id')
+            OR chirps.user_id IN (SELECT followee_id FROM follows WHERE follower_id = sqlc.arg('user_id')))
+        AND NOT EXISTS (SELECT 1 FROM rechirps
+            WHERE rechirps.chirp_id = chirps.id
+                AND (rechirps.user_id = sqlc.arg('user_id')
+                    OR rechirps.user_id IN (SELECT followee_id FROM follows WHERE follower_id = sqlc.arg('user_id')))
+                AND user_visible_to(rechirps.user_id, sqlc.arg('user_id'), TRUE))
+        AND chirps.tombstoned_at IS NULL
+        AND chirps.deleted_at IS NULL
+        AND chirp_visible_to(chirps.id, sqlc.arg('user_id'), TRUE)
+        AND (sqlc.narg('cursor_created_at')::timestamp IS NULL
+            OR (chirps.created_at, chirps.id) > (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+    ORDER BY chirps.created_at ASC, chirps.id ASC
+    LIMIT sqlc.arg('limit'))
+    UNION ALL
+    (SELECT rechirps.chirp_id, rechirps.created_at, rechirps.user_id
+    FROM rechirps
+    JOIN chirps ON chirps.id = rechirps.chirp_id
+    WHERE (rechirps.user_id = sqlc.arg('user_id')
+            OR rechirps.user_id IN (SELECT followee_id FROM follows WHERE follower_id = sqlc.arg('user_id')))
+        AND user_visible_to(rechirps.user_id, sqlc.arg('user_id'), TRUE)
+        AND NOT EXISTS (SELECT 1 FROM rechirps AS later
+            WHERE later.chirp_id = rechirps.chirp_id
+                AND (later.user_id = sqlc.arg('user_id')
+                    OR later.user_id IN (SELECT followee_id FROM follows WHERE follower_id = sqlc.arg('user_id')))
+                AND user_visible_to(later.user_id, sqlc.arg('user_id'), TRUE)
+                AND (later.created_at, later.user_id) > (rechirps.created_at, rechirps.user_id))
+        AND chirps.tombstoned_at IS NULL
+        AND chirps.deleted_at IS NULL
+        AND chirp_visible_to(chirps.id, sqlc.arg('user_id'), TRUE)
+        AND (sqlc.narg('cursor_created_at')::timestamp IS NULL
+            OR (rechirps.created_at, rechirps.chirp_id) > (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+    ORDER BY rechirps.created_at ASC, rechirps.chirp_id ASC
+    LIMIT sqlc.arg('limit'))
+) AS feed
+JOIN chirps ON chirps.id = feed.chirp_id
+ORDER BY feed.active_at ASC, chirps.id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: GetChirpsByUserID :many
+SELECT sqlc.embed(chirps), feed.active_at, feed.rechirped_by
+FROM (
+    (SELECT chirps.id AS chirp_id, chirps.created_at AS active_at, NULL::uuid AS rechirped_by
+    FROM chirps
+    WHERE chirps.user_id = sqlc.arg('user_id')
+        AND NOT EXISTS (SELECT 1 FROM rechirps
+            WHERE rechirps.chirp_id = chirps.id
+                AND rechirps.user_id = sqlc.arg('user_id')
+                AND user_visible_to(rechirps.user_id, sqlc.narg('viewer_id')::uuid, TRUE))
+        AND chirps.tombstoned_at IS NULL
+        AND chirps.deleted_at IS NULL
+        AND chirp_visible_to(chirps.id, sqlc.narg('viewer_id')::uuid, TRUE)
+        AND (sqlc.narg('cursor_created_at')::timestamp IS NULL
+            OR (chirps.created_at, chirps.id) > (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+    ORDER BY chirps.created_at ASC, chirps.id ASC
+    LIMIT sqlc.arg('limit'))
+    UNION ALL
+    (SELECT rechirps.chirp_id, rechirps.created_at, rechirps.user_id
+    FROM rechirps
+    JOIN chirps ON chirps.id = rechirps.chirp_id
+    WHERE rechirps.user_id = sqlc.arg('user_id')
+        AND user_visible_to(rechirps.user_id, sqlc.narg('viewer_id')::uuid, TRUE)
+        AND NOT EXISTS (SELECT 1 FROM rechirps AS later
+            WHERE later.chirp_id = rechirps.chirp_id
+                AND later.user_id = sqlc.arg('user_id')
+                AND user_visible_to(later.user_id, sqlc.narg('viewer_id')::uuid, TRUE)
+                AND (later.created_at, later.user_id) > (rechirps.created_at, rechirps.user_id))
+        AND chirps.tombstoned_at IS NULL
+        AND chirps.deleted_at IS NULL
+        AND chirp_visible_to(chirps.id, sqlc.narg('viewer_id')::uuid, TRUE)
+        AND (sqlc.narg('cursor_created_at')::timestamp IS NULL
+            OR (rechirps.created_at, rechirps.chirp_id) > (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+    ORDER BY rechirps.created_at ASC, rechirps.chirp_id ASC
+    LIMIT sqlc.arg('limit'))
+) AS feed
+JOIN chirps ON chirps.id = feed.chirp_id
+ORDER BY feed.active_at ASC, chirps.id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: GetChirpsByUserIDDesc :many
+SELECT sqlc.embed(chirps), feed.active_at, feed.rechirped_by
+FROM (
+    (SELECT chirps.id AS chirp_id, chirps.created_at AS active_at, NULL::uuid AS rechirped_by
+    FROM chirps
+    WHERE chirps.user_id = sqlc.arg('user_id')
+        AND NOT EXISTS (SELECT 1 FROM rechirps
+            WHERE rechirps.chirp_id = chirps.id
+                AND rechirps.user_id = sqlc.arg('user_id')
+                AND user_visible_to(rechirps.user_id, sqlc.narg('viewer_id')::uuid, TRUE))
+        AND chirps.tombstoned_at IS NULL
+        AND chirps.deleted_at IS NULL
+        AND chirp_visible_to(chirps.id, sqlc.narg('viewer_id')::uuid, TRUE)
+        AND (sqlc.narg('cursor_created_at')::timestamp IS NULL
+            OR (chirps.created_at, chirps.id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+    ORDER BY chirps.created_at DESC, chirps.id DESC
+    LIMIT sqlc.arg('limit'))
+    UNION ALL
+    (SELECT rechirps.chirp_id, rechirps.created_at, rechirps.user_id
+    FROM rechirps
+    JOIN chirps ON chirps.id = rechirps.chirp_id
+    WHERE rechirps.user_id = sqlc.arg('user_id')
+        AND user_visible_to(rechirps.user_id, sqlc.narg('viewer_id')::uuid, TRUE)
+        AND NOT EXISTS (SELECT 1 FROM rechirps AS later
+            WHERE later.chirp_id = rechirps.chirp_id
+                AND later.user_id = sqlc.arg('user_id')
+                AND user_visible_to(later.user_id, sqlc.narg('viewer_id')::uuid, TRUE)
+                AND (later.created_at, later.user_id) > (rechirps.created_at, rechirps.user_id))
+        AND chirps.tombstoned_at IS NULL
+        AND chirps.deleted_at IS NULL
+        AND chirp_visible_to(chirps.id, sqlc.narg('viewer_id')::uuid, TRUE)
+        AND (sqlc.narg('cursor_created_at')::timestamp IS NULL
+            OR (rechirps.created_at, rechirps.chirp_id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+    ORDER BY rechirps.created_at DESC, rechirps.chirp_id DESC
+    LIMIT sqlc.arg('limit'))
+) AS feed
+JOIN chirps ON chirps.id = feed.chirp_id
+ORDER BY feed.active_at DESC, chirps.id DESC
+LIMIT sqlc.arg('limit');
 
 -- name: GetChirpByID :one
 SELECT * FROM chirps
+WHERE id = sqlc.arg('id')
+    AND deleted_at IS NULL
+    AND chirp_visible_to(id, sqlc.narg('viewer_id')::uuid, FALSE);
+
+-- name: GetChirpsByIDs :many
+SELECT * FROM chirps
+WHERE id = ANY(sqlc.arg('ids')::uuid[])
+    AND tombstoned_at IS NULL
+    AND deleted_at IS NULL
+    AND chirp_visible_to(id, sqlc.narg('viewer_id')::uuid, FALSE);
+
+-- name: TrashChirp :exec
+UPDATE chirps
+SET deleted_at = NOW(),
+    deleted_by = sqlc.arg('deleted_by')
+WHERE id = sqlc.arg('id')
+    AND deleted_at IS NULL;
+
+-- name: RestoreChirp :one
+UPDATE chirps
+SET deleted_at = NULL,
+    deleted_by = NULL
+WHERE id = sqlc.arg('id')
+    AND user_id = sqlc.arg('user_id')
+    AND deleted_by = sqlc.arg('user_id')
+    AND deleted_at > sqlc.arg('deleted_after')
+RETURNING *;
+
+-- name: GetTrashedChirps :many
+SELECT * FROM chirps
+WHERE user_id = sqlc.arg('user_id')
+    AND deleted_by = sqlc.arg('user_id')
+    AND deleted_at > sqlc.arg('deleted_after')
+    AND (sqlc.narg('cursor_created_at')::timestamp IS NULL
+        OR (deleted_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY deleted_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: GetDeletedChirps :many
+SELECT * FROM chirps
+WHERE deleted_at IS NOT NULL
+    AND (sqlc.narg('user_id')::uuid IS NULL OR user_id = sqlc.narg('user_id')::uuid)
+    AND (sqlc.narg('cursor_created_at')::timestamp IS NULL
+        OR (deleted_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY deleted_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: GetChirpIncludingDeleted :one
+SELECT * FROM chirps
 WHERE id = $1;
 
+-- name: ClaimExpiredTrashedChirp :one
+SELECT * FROM chirps
+WHERE deleted_at <= $1
+    AND deleted_by = user_id
+    AND (purge_failed_at IS NULL OR purge_failed_at <= $2)
+ORDER BY deleted_at ASC, id ASC
+LIMIT 1
+FOR UPDATE SKIP LOCKED;
+
 -- name: DeleteChirp :exec
 DELETE FROM chirps
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: RecordChirpPurgeFailure :exec
+UPDATE chirps
+SET purge_failed_at = NOW()
+WHERE id = $1;
+
+-- name: SearchChirps :many
+SELECT sqlc.embed(chirps),
+    ts_rank(search_vector, to_tsquery('english', sqlc.arg('query')))::real AS rank,
+    ts_headline('english', body, to_tsquery('english', sqlc.arg('query')),
+        'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, FragmentDelimiter=" … "')::text AS snippet
+FROM chirps
+WHERE search_vector @@ to_tsquery('english', sqlc.arg('query'))
+    AND tombstoned_at IS NULL
+    AND deleted_at IS NULL
+    AND chirp_visible_to(id, sqlc.narg('viewer_id')::uuid, TRUE)
+    AND (sqlc.narg('cursor_rank')::real IS NULL
+        OR (ts_rank(search_vector, to_tsquery('english', sqlc.arg('query')))::real, created_at, id)
+            < (sqlc.narg('cursor_rank')::real, sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY rank DESC, created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: GetChirpByIDForUpdate :one
+SELECT * FROM chirps
+WHERE id = $1
+    AND deleted_at IS NULL
+FOR UPDATE;
+
+-- name: UpdateChirpBody :one
+UPDATE chirps
+SET body = sqlc.arg('body'),
+    held_for_review = held_for_review OR sqlc.arg('held_for_review'),
+    updated_at = NOW()
+WHERE id = sqlc.arg('id')
+    AND deleted_at IS NULL
+    AND created_at > NOW() - make_interval(secs => sqlc.arg('edit_window_seconds')::float8)
+RETURNING *;
+
+-- name: TombstoneChirp :exec
+UPDATE chirps
+SET body = '',
+    content_warning = '',
+    tombstoned_at = NOW(),
+    deleted_at = NULL,
+    deleted_by = NULL,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: CountChirpReplies :one
+SELECT COUNT(*) FROM chirps
+WHERE parent_id = sqlc.arg('parent_id')::uuid;
+
+-- name: GetReplyCounts :many
+SELECT parent_id::uuid AS chirp_id, COUNT(*) AS reply_count
+FROM chirps
+WHERE parent_id = ANY(sqlc.arg('chirp_ids')::uuid[])
+    AND tombstoned_at IS NULL
+    AND deleted_at IS NULL
+    AND chirp_visible_to(id, sqlc.narg('viewer_id')::uuid, FALSE)
+GROUP BY parent_id;
+
+-- name: GetChirpThread :many
+WITH RECURSIVE thread(id, depth) AS (
+    SELECT chirps.id, 0 FROM chirps
+    WHERE chirps.id = sqlc.arg('root_id')
+        AND (chirps.deleted_at IS NULL
+            OR EXISTS (SELECT 1 FROM chirps AS replies
+                WHERE replies.parent_id = chirps.id
+                    AND replies.deleted_at IS NULL
+                    AND chirp_visible_to(replies.id, sqlc.narg('viewer_id')::uuid, FALSE)))
+        AND chirp_visible_to(chirps.id, sqlc.narg('viewer_id')::uuid, FALSE)
+    UNION ALL
+    SELECT chirps.id, thread.depth + 1 FROM chirps
+    JOIN thread ON chirps.parent_id = thread.id
+    WHERE thread.depth < sqlc.arg('max_depth')::int
+        AND (chirps.deleted_at IS NULL
+            OR EXISTS (SELECT 1 FROM chirps AS replies
+                WHERE replies.parent_id = chirps.id
+                    AND replies.deleted_at IS NULL
+                    AND chirp_visible_to(replies.id, sqlc.narg('viewer_id')::uuid, FALSE)))
+        AND chirp_visible_to(chirps.id, sqlc.narg('viewer_id')::uuid, FALSE)
+)
+SELECT sqlc.embed(chirps), thread.depth::int AS depth
+FROM thread
+JOIN chirps ON chirps.id = thread.id
+ORDER BY thread.depth, chirps.created_at, chirps.id
+LIMIT sqlc.arg('limit');
+
+-- name: SetChirpSensitive :one
+UPDATE chirps
+SET sensitive = sqlc.arg('sensitive'),
+    updated_at = NOW()
+WHERE id = sqlc.arg('id')
+    AND tombstoned_at IS NULL
+    AND deleted_at IS NULL
+RETURNING *;
+
+-- name: GetHeldChirps :many
+SELECT * FROM chirps
+WHERE held_for_review
+    AND tombstoned_at IS NULL
+    AND deleted_at IS NULL
+    AND (sqlc.narg('cursor_created_at')::timestamp IS NULL
+        OR (created_at, id) > (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: ApproveHeldChirp :one
+UPDATE chirps
+SET held_for_review = FALSE
+WHERE id = $1
+    AND held_for_review
+    AND tombstoned_at IS NULL
+    AND deleted_at IS NULL
+RETURNING *;
@@ -0,0 +1,98 @@
+-- name: CreateConversation :one
+INSERT INTO conversations(id, created_at, updated_at)
+VALUES (gen_random_uuid(), NOW(), NOW())
+RETURNING *;
+
+-- name: AddConversationParticipant :exec
+INSERT INTO conversation_participants(conversation_id, user_id, joined_at, last_read_at, deleted_at)
+VALUES ($1, $2, NOW(), NULL, NULL);
+
+-- name: FindDirectConversation :one
+SELECT conversations.* FROM conversations
+JOIN conversation_participants AS mine
+    ON mine.conversation_id = conversations.id AND mine.user_id = sqlc.arg('user_id')
+JOIN conversation_participants AS theirs
+    ON theirs.conversation_id = conversations.id AND theirs.user_id = sqlc.arg('other_user_id')
+WHERE (SELECT COUNT(*) FROM conversation_participants
+    WHERE conversation_participants.conversation_id = conversations.id) = 2
+LIMIT 1;
+
+-- name: GetConversationParticipant :one
+SELECT * FROM conversation_participants
+WHERE conversation_id = $1
+    AND user_id = $2;
+
+-- name: GetConversationParticipants :many
+SELECT * FROM conversation_participants
+WHERE conversation_id = ANY(sqlc.arg('conversation_ids')::uuid[])
+ORDER BY joined_at, user_id;
+
+-- name: GetConversations :many
+SELECT sqlc.embed(conversations),
+    (SELECT COUNT(*) FROM messages
+        WHERE messages.conversation_id = conversations.id
+            AND messages.sender_id <> sqlc.arg('user_id')
+            AND messages.created_at > COALESCE(
+                GREATEST(conversation_participants.last_read_at, conversation_participants.deleted_at),
+                '-infinity'::timestamp)) AS unread_count
+FROM conversation_participants
+JOIN conversations ON conversations.id = conversation_participants.conversation_id
+WHERE conversation_participants.user_id = sqlc.arg('user_id')
+    AND (conversation_participants.deleted_at IS NULL
+        OR conversations.updated_at > conversation_participants.deleted_at)
+    AND (sqlc.narg('cursor_created_at')::timestamp IS NULL
+        OR (conversations.updated_at, conversations.id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY conversations.updated_at DESC, conversations.id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ConversationHasBlock :one
+SELECT EXISTS (
+    SELECT 1 FROM conversation_participants
+    JOIN blocks
+        ON (blocks.blocker_id = conversation_participants.user_id AND blocks.blocked_id = sqlc.arg('user_id'))
+        OR (blocks.blocker_id = sqlc.arg('user_id') AND blocks.blocked_id = conversation_participants.user_id)
+    WHERE conversation_participants.conversation_id = sqlc.arg('conversation_id')
+);
+
+-- name: CreateMessage :one
+INSERT INTO messages(id, conversation_id, sender_id, body, created_at)
+VALUES (gen_random_uuid(), $1, $2, $3, NOW())
+RETURNING *;
+
+-- name: TouchConversation :exec
+UPDATE conversations
+SET updated_at = $2
+WHERE id = $1;
+
+-- name: GetMessages :many
+SELECT * FROM messages
+WHERE conversation_id = sqlc.arg('conversation_id')
+    AND (sqlc.narg('visible_after')::timestamp IS NULL
+        OR created_at > sqlc.narg('visible_after')::timestamp)
+    AND (sqlc.narg('cursor_created_at')::timestamp IS NULL
+        OR (created_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: MarkConversationRead :exec
+UPDATE conversation_participants
+SET last_read_at = NOW()
+WHERE conversation_id = $1
+    AND user_id = $2;
+
+-- name: DeleteConversationForUser :exec
+UPDATE conversation_participants
+SET deleted_at = NOW(),
+    last_read_at = NOW()
+WHERE conversation_id = $1
+    AND user_id = $2;
+
+-- name: DeleteAbandonedConversation :exec
+DELETE FROM conversations
+WHERE conversations.id = $1
+    AND NOT EXISTS (
+        SELECT 1 FROM conversation_participants
+        WHERE conversation_participants.conversation_id = conversations.id
+            AND (conversation_participants.deleted_at IS NULL
+                OR conversation_participants.deleted_at < conversations.updated_at)
+    );
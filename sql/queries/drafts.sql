@@ -0,0 +1,47 @@
+-- name: CreateDraft :one
+INSERT INTO drafts(
+    id, created_at, updated_at, user_id, body, parent_id, quoted_chirp_id,
+    visibility, content_warning, sensitive, version
+)
+VALUES ($1, NOW(), NOW(), $2, $3, $4, $5, $6, $7, $8, 1)
+ON CONFLICT (id) DO NOTHING
+RETURNING *;
+
+-- name: UpdateDraft :one
+UPDATE drafts
+SET body = sqlc.arg('body'),
+    parent_id = sqlc.narg('parent_id'),
+    quoted_chirp_id = sqlc.narg('quoted_chirp_id'),
+    visibility = sqlc.arg('visibility'),
+    content_warning = sqlc.arg('content_warning'),
+    sensitive = sqlc.arg('sensitive'),
+    version = version + 1,
+    updated_at = NOW()
+WHERE id = sqlc.arg('id')
+    AND user_id = sqlc.arg('user_id')
+    AND version = sqlc.arg('version')
+RETURNING *;
+
+-- name: GetDraft :one
+SELECT * FROM drafts
+WHERE id = $1
+    AND user_id = $2;
+
+-- name: GetDraftForUpdate :one
+SELECT * FROM drafts
+WHERE id = $1
+    AND user_id = $2
+FOR UPDATE;
+
+-- name: GetDrafts :many
+SELECT * FROM drafts
+WHERE user_id = sqlc.arg('user_id')
+    AND (sqlc.narg('cursor_updated_at')::timestamp IS NULL
+        OR (updated_at, id) < (sqlc.narg('cursor_updated_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY updated_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: DeleteDraft :execrows
+DELETE FROM drafts
+WHERE id = $1
+    AND user_id = $2;
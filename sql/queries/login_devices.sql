@@ -0,0 +1,14 @@
+-- name: CountLoginDevices :one
+SELECT COUNT(*) FROM login_devices
+WHERE user_id = $1;
+
+-- name: CreateLoginDevice :execrows
+INSERT INTO login_devices(user_id, device_hash, first_seen_at, last_seen_at)
+VALUES ($1, $2, NOW(), NOW())
+ON CONFLICT (user_id, device_hash) DO NOTHING;
+
+-- name: TouchLoginDevice :exec
+UPDATE login_devices
+SET last_seen_at = NOW()
+WHERE user_id = $1
+    AND device_hash = $2;
@@ -0,0 +1,49 @@
+-- name: CreateMediaAttachment :one
+INSERT INTO media_attachments(
+    id, user_id, chirp_id, position, content_type, storage_key, thumbnail_key,
+    width, height, thumbnail_width, thumbnail_height, size_bytes, alt_text, created_at
+)
+VALUES ($1, $2, NULL, 0, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW())
+RETURNING *;
+
+-- name: AttachMediaToChirp :execrows
+UPDATE media_attachments
+SET chirp_id = sqlc.arg('chirp_id'),
+    position = array_position(sqlc.arg('media_ids')::uuid[], id)
+WHERE id = ANY(sqlc.arg('media_ids')::uuid[])
+    AND user_id = sqlc.arg('user_id')
+    AND chirp_id IS NULL
+    AND scheduled_chirp_id IS NULL;
+
+-- name: GetChirpMedia :many
+SELECT * FROM media_attachments
+WHERE chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[])
+ORDER BY chirp_id, position;
+
+-- name: DeleteChirpMedia :exec
+DELETE FROM media_attachments
+WHERE chirp_id = $1;
+
+-- name: ReserveMediaForScheduledChirp :execrows
+UPDATE media_attachments
+SET scheduled_chirp_id = sqlc.arg('scheduled_chirp_id'),
+    position = array_position(sqlc.arg('media_ids')::uuid[], id)
+WHERE id = ANY(sqlc.arg('media_ids')::uuid[])
+    AND user_id = sqlc.arg('user_id')
+    AND chirp_id IS NULL
+    AND scheduled_chirp_id IS NULL;
+
+-- name: AttachScheduledMediaToChirp :exec
+UPDATE media_attachments
+SET chirp_id = sqlc.arg('chirp_id'),
+    scheduled_chirp_id = NULL
+WHERE scheduled_chirp_id = sqlc.arg('scheduled_chirp_id');
+
+-- name: GetScheduledChirpMedia :many
+SELECT * FROM media_attachments
+WHERE scheduled_chirp_id = ANY(sqlc.arg('scheduled_chirp_ids')::uuid[])
+ORDER BY scheduled_chirp_id, position;
+
+-- name: GetMediaAttachmentByKey :one
+SELECT * FROM media_attachments
+WHERE storage_key = sqlc.arg('key') OR thumbnail_key = sqlc.arg('key');
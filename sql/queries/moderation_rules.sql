@@ -0,0 +1,21 @@
+-- name: CreateModerationRule :one
+INSERT INTO moderation_rules(id, created_at, updated_at, kind, pattern, action)
+VALUES (gen_random_uuid(), NOW(), NOW(), $1, $2, $3)
+RETURNING *;
+
+-- name: GetModerationRules :many
+SELECT * FROM moderation_rules
+ORDER BY created_at ASC, id ASC;
+
+-- name: UpdateModerationRule :one
+UPDATE moderation_rules
+SET kind = sqlc.arg('kind'),
+    pattern = sqlc.arg('pattern'),
+    action = sqlc.arg('action'),
+    updated_at = NOW()
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: DeleteModerationRule :execrows
+DELETE FROM moderation_rules
+WHERE id = $1;
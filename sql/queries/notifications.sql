@@ -0,0 +1,30 @@
+-- name: CreateNotification :one
+INSERT INTO notifications(id, user_id, type, payload, created_at, read_at)
+VALUES (gen_random_uuid(), $1, $2, $3, NOW(), NULL)
+RETURNING *;
+
+-- name: GetNotifications :many
+SELECT * FROM notifications
+WHERE user_id = sqlc.arg('user_id')
+    AND (NOT sqlc.arg('unread_only')::bool OR read_at IS NULL)
+    AND (sqlc.narg('cursor_created_at')::timestamp IS NULL
+        OR (created_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: CountUnreadNotifications :one
+SELECT COUNT(*) FROM notifications
+WHERE user_id = $1
+    AND read_at IS NULL;
+
+-- name: MarkNotificationRead :execrows
+UPDATE notifications
+SET read_at = COALESCE(read_at, NOW())
+WHERE id = $1
+    AND user_id = $2;
+
+-- name: MarkAllNotificationsRead :exec
+UPDATE notifications
+SET read_at = NOW()
+WHERE user_id = $1
+    AND read_at IS NULL;
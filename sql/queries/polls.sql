@@ -0,0 +1,47 @@
+-- name: CreatePoll :exec
+INSERT INTO polls(chirp_id, closes_at, created_at)
+VALUES ($1, $2, NOW());
+
+-- name: CreatePollOptions :exec
+INSERT INTO poll_options(id, chirp_id, position, text)
+SELECT gen_random_uuid(), sqlc.arg('chirp_id')::uuid, options.position::int, options.text
+FROM unnest(sqlc.arg('texts')::text[]) WITH ORDINALITY AS options(text, position);
+
+-- name: GetPoll :one
+SELECT * FROM polls
+WHERE chirp_id = $1;
+
+-- name: GetPollOptions :many
+SELECT sqlc.embed(poll_options), polls.closes_at
+FROM poll_options
+JOIN polls ON polls.chirp_id = poll_options.chirp_id
+WHERE poll_options.chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[])
+ORDER BY poll_options.chirp_id, poll_options.position;
+
+-- name: PollOptionExists :one
+SELECT EXISTS (
+    SELECT 1 FROM poll_options
+    WHERE id = sqlc.arg('id')
+        AND chirp_id = sqlc.arg('chirp_id')
+);
+
+-- name: CastPollVote :execrows
+INSERT INTO poll_votes(chirp_id, user_id, option_id, created_at)
+VALUES ($1, $2, $3, NOW())
+ON CONFLICT (chirp_id, user_id) DO NOTHING;
+
+-- name: GetPollVotes :many
+SELECT chirp_id, option_id FROM poll_votes
+WHERE user_id = sqlc.arg('user_id')
+    AND chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[]);
+
+-- name: GetPollVoteCounts :many
+SELECT option_id, COUNT(*) AS vote_count
+FROM poll_votes
+WHERE chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[])
+    AND user_visible_to(user_id, sqlc.narg('viewer_id')::uuid, FALSE)
+GROUP BY option_id;
+
+-- name: DeletePoll :exec
+DELETE FROM polls
+WHERE chirp_id = $1;
@@ -0,0 +1,16 @@
+-- name: LockRateLimitBucket :one
+INSERT INTO rate_limit_buckets(key, tokens, updated_at, full_at)
+VALUES ($1, 0, '0001-01-01', '0001-01-01')
+ON CONFLICT (key) DO UPDATE SET key = EXCLUDED.key
+RETURNING *;
+
+-- name: SaveRateLimitBucket :exec
+UPDATE rate_limit_buckets
+SET tokens = $2,
+    updated_at = $3,
+    full_at = $4
+WHERE key = $1;
+
+-- name: DeleteFullRateLimitBuckets :exec
+DELETE FROM rate_limit_buckets
+WHERE full_at <= $1;
@@ -0,0 +1,20 @@
+-- name: CreateRechirp :exec
+INSERT INTO rechirps(user_id, chirp_id, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT (user_id, chirp_id) DO NOTHING;
+
+-- name: DeleteRechirp :exec
+DELETE FROM rechirps
+WHERE user_id = $1
+    AND chirp_id = $2;
+
+-- name: DeleteChirpRechirps :exec
+DELETE FROM rechirps
+WHERE chirp_id = $1;
+
+-- name: GetRechirpCounts :many
+SELECT chirp_id, COUNT(*) AS rechirp_count
+FROM rechirps
+WHERE chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[])
+    AND user_visible_to(user_id, sqlc.narg('viewer_id')::uuid, FALSE)
+GROUP BY chirp_id;
@@ -0,0 +1,78 @@
+-- name: OpenChirpReportCase :one
+INSERT INTO report_cases(id, created_at, updated_at, chirp_id, reported_user_id, status)
+VALUES (gen_random_uuid(), NOW(), NOW(), $1, $2, 'open')
+ON CONFLICT (chirp_id) WHERE chirp_id IS NOT NULL AND status <> 'resolved'
+DO UPDATE SET updated_at = NOW()
+RETURNING *;
+
+-- name: OpenUserReportCase :one
+INSERT INTO report_cases(id, created_at, updated_at, chirp_id, reported_user_id, status)
+VALUES (gen_random_uuid(), NOW(), NOW(), NULL, $1, 'open')
+ON CONFLICT (reported_user_id) WHERE chirp_id IS NULL AND status <> 'resolved'
+DO UPDATE SET updated_at = NOW()
+RETURNING *;
+
+-- name: CreateReport :one
+INSERT INTO reports(id, created_at, case_id, reporter_id, reason, comment)
+VALUES (gen_random_uuid(), NOW(), $1, $2, $3, $4)
+ON CONFLICT (case_id, reporter_id) DO NOTHING
+RETURNING *;
+
+-- name: CreateReportCaseEvent :exec
+INSERT INTO report_case_events(id, created_at, case_id, actor_id, action, note)
+VALUES (gen_random_uuid(), NOW(), $1, $2, $3, $4);
+
+-- name: GetReportCases :many
+SELECT sqlc.embed(report_cases),
+    (SELECT COUNT(*) FROM reports WHERE reports.case_id = report_cases.id) AS report_count
+FROM report_cases
+WHERE status = sqlc.arg('status')
+    AND (sqlc.narg('cursor_created_at')::timestamp IS NULL
+        OR (created_at, id) > (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: GetReportCase :one
+SELECT * FROM report_cases
+WHERE id = $1;
+
+-- name: GetReportCaseForUpdate :one
+SELECT * FROM report_cases
+WHERE id = $1
+FOR UPDATE;
+
+-- name: GetCaseReports :many
+SELECT * FROM reports
+WHERE case_id = $1
+ORDER BY created_at, id;
+
+-- name: GetReportCaseEvents :many
+SELECT * FROM report_case_events
+WHERE case_id = $1
+ORDER BY created_at, id;
+
+-- name: ClaimReportCase :one
+UPDATE report_cases
+SET status = 'claimed',
+    claimed_by = sqlc.arg('claimed_by'),
+    updated_at = NOW()
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: ReleaseReportCase :one
+UPDATE report_cases
+SET status = 'open',
+    claimed_by = NULL,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: ResolveReportCase :one
+UPDATE report_cases
+SET status = 'resolved',
+    resolution = sqlc.arg('resolution'),
+    resolution_note = sqlc.arg('resolution_note'),
+    resolved_at = NOW(),
+    updated_at = NOW()
+WHERE id = sqlc.arg('id')
+RETURNING *;
@@ -0,0 +1,47 @@
+-- name: CreateScheduledChirp :one
+INSERT INTO scheduled_chirps(
+    id, created_at, updated_at, publish_at, body, user_id, parent_id, quoted_chirp_id,
+    visibility, content_warning, sensitive
+)
+VALUES (gen_random_uuid(), NOW(), NOW(), $1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: GetScheduledChirps :many
+SELECT * FROM scheduled_chirps
+WHERE user_id = sqlc.arg('user_id')
+    AND (sqlc.narg('cursor_publish_at')::timestamp IS NULL
+        OR (publish_at, id) > (sqlc.narg('cursor_publish_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY publish_at ASC, id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: RescheduleChirp :one
+UPDATE scheduled_chirps
+SET publish_at = sqlc.arg('publish_at'),
+    updated_at = NOW()
+WHERE id = sqlc.arg('id')
+    AND user_id = sqlc.arg('user_id')
+RETURNING *;
+
+-- name: CancelScheduledChirp :execrows
+DELETE FROM scheduled_chirps
+WHERE id = sqlc.arg('id')
+    AND user_id = sqlc.arg('user_id');
+
+-- name: ClaimDueScheduledChirp :one
+SELECT * FROM scheduled_chirps
+WHERE publish_at <= NOW()
+    AND (last_failed_at IS NULL OR last_failed_at <= sqlc.arg('retry_before'))
+ORDER BY publish_at, id
+LIMIT 1
+FOR UPDATE SKIP LOCKED;
+
+-- name: DeleteScheduledChirp :exec
+DELETE FROM scheduled_chirps
+WHERE id = $1;
+
+-- name: RecordScheduledChirpFailure :one
+UPDATE scheduled_chirps
+SET failed_attempts = failed_attempts + 1,
+    last_failed_at = NOW()
+WHERE id = $1
+RETURNING *;
@@ -0,0 +1,43 @@
+-- name: TagChirp :exec
+WITH chirp_tag_ids AS (
+    INSERT INTO tags(id, name, created_at)
+    SELECT gen_random_uuid(), name, NOW()
+    FROM unnest(sqlc.arg('names')::text[]) AS name
+    ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+    RETURNING id
+)
+INSERT INTO chirp_tags(chirp_id, tag_id, created_at)
+SELECT sqlc.arg('chirp_id')::uuid, id, sqlc.arg('created_at')::timestamp
+FROM chirp_tag_ids
+ON CONFLICT (chirp_id, tag_id) DO NOTHING;
+
+-- name: DeleteChirpTags :exec
+DELETE FROM chirp_tags
+WHERE chirp_id = $1;
+
+-- name: GetTagChirps :many
+SELECT chirps.* FROM chirp_tags
+JOIN tags ON tags.id = chirp_tags.tag_id
+JOIN chirps ON chirps.id = chirp_tags.chirp_id
+WHERE tags.name = sqlc.arg('name')
+    AND chirps.tombstoned_at IS NULL
+    AND chirps.deleted_at IS NULL
+    AND chirp_visible_to(chirps.id, sqlc.narg('viewer_id')::uuid, TRUE)
+    AND (sqlc.narg('cursor_created_at')::timestamp IS NULL
+        OR (chirp_tags.created_at, chirp_tags.chirp_id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY chirp_tags.created_at DESC, chirp_tags.chirp_id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: GetTrendingTags :many
+SELECT tags.name,
+    COUNT(*) AS uses,
+    SUM(EXP(-EXTRACT(EPOCH FROM NOW() - chirp_tags.created_at)::float8 / sqlc.arg('decay_seconds')::float8))::float8 AS score
+FROM chirp_tags
+JOIN tags ON tags.id = chirp_tags.tag_id
+JOIN chirps ON chirps.id = chirp_tags.chirp_id
+WHERE chirp_tags.created_at > NOW() - make_interval(secs => sqlc.arg('window_seconds')::float8)
+    AND chirps.deleted_at IS NULL
+    AND chirp_visible_to(chirps.id, NULL, TRUE)
+GROUP BY tags.name
+ORDER BY score DESC, tags.name
+LIMIT sqlc.arg('limit');
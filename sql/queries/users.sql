@@ -1,6 +1,12 @@
 -- name: CreateUser :one
-INSERT INTO users(id, created_at, updated_at, email, hashed_password)
-VALUES (gen_random_uuid(), NOW(), NOW(), $1, $2)
+WITH new_user AS (
+    SELECT gen_random_uuid() AS id
+)
+INSERT INTO users(id, created_at, updated_at, email, hashed_password, handle, display_name, bio, location)
+SELECT id, NOW(), NOW(), sqlc.arg('email'), sqlc.arg('hashed_password'),
+    COALESCE(sqlc.narg('handle'), 'user_' || left(replace(id::text, '-', ''), 12)),
+    sqlc.arg('display_name'), sqlc.arg('bio'), sqlc.arg('location')
+FROM new_user
 RETURNING *;
 
 -- name: Reset :exec
@@ -12,10 +18,15 @@ WHERE email =  $1;
 
 -- name: UpdateUser :one
 UPDATE users
-SET email = $2,
-    hashed_password = $3,
+SET email = COALESCE(sqlc.narg('email'), email),
+    hashed_password = COALESCE(sqlc.narg('hashed_password'), hashed_password),
+    handle = COALESCE(sqlc.narg('handle'), handle),
+    display_name = COALESCE(sqlc.narg('display_name'), display_name),
+    bio = COALESCE(sqlc.narg('bio'), bio),
+    location = COALESCE(sqlc.narg('location'), location),
+    sensitive_content = COALESCE(sqlc.narg('sensitive_content'), sensitive_content),
     updated_at = NOW()
-WHERE id = $1
+WHERE id = sqlc.arg('id')
 RETURNING *;
 
 -- name: UpgradeUser :one
@@ -23,4 +34,43 @@ UPDATE users
 SET is_chirpy_red = TRUE,
     updated_at = NOW()
 WHERE id = $1
-RETURNING *;
\ No newline at end of file
+    AND NOT is_chirpy_red
+RETURNING *;
+-- name: GetUserByID :one
+SELECT * FROM users
+WHERE id = $1;
+
+-- name: GetUserByHandle :one
+SELECT * FROM users
+WHERE lower(handle) = lower(sqlc.arg('handle'));
+
+-- name: CountUsersByIDs :one
+SELECT COUNT(*) FROM users
+WHERE id = ANY(sqlc.arg('ids')::uuid[]);
+
+-- name: SuspendUser :one
+UPDATE users
+SET suspended_at = NOW(),
+    suspended_until = sqlc.narg('suspended_until'),
+    suspension_reason = sqlc.arg('suspension_reason'),
+    suspension_hides_chirps = sqlc.arg('suspension_hides_chirps'),
+    updated_at = NOW()
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: UnsuspendUser :execrows
+UPDATE users
+SET suspended_at = NULL,
+    suspended_until = NULL,
+    suspension_reason = '',
+    suspension_hides_chirps = FALSE,
+    updated_at = NOW()
+WHERE id = $1
+    AND suspended_at IS NOT NULL;
+
+-- name: SetUserShadowBanned :one
+UPDATE users
+SET shadow_banned = sqlc.arg('shadow_banned'),
+    updated_at = NOW()
+WHERE id = sqlc.arg('id')
+RETURNING *;
@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE chirps
+ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (to_tsvector('english', body)) STORED;
+
+CREATE INDEX chirps_search_vector_idx ON chirps USING GIN (search_vector);
+
+-- +goose Down
+DROP INDEX chirps_search_vector_idx;
+
+ALTER TABLE chirps
+DROP COLUMN search_vector;
@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE chirps
+ADD COLUMN parent_id UUID REFERENCES chirps(id) ON DELETE SET NULL,
+ADD COLUMN tombstoned_at TIMESTAMP;
+
+CREATE INDEX chirps_parent_id_idx ON chirps(parent_id, created_at);
+
+-- +goose Down
+DROP INDEX chirps_parent_id_idx;
+
+ALTER TABLE chirps
+DROP COLUMN tombstoned_at,
+DROP COLUMN parent_id;
@@ -0,0 +1,24 @@
+-- +goose Up
+CREATE TABLE rechirps(
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, chirp_id)
+);
+
+CREATE INDEX rechirps_chirp_id_idx ON rechirps(chirp_id);
+
+-- no foreign key: a quote keeps pointing at the original after it has been
+-- deleted, so clients can tell a quote of a removed chirp from a plain chirp
+ALTER TABLE chirps
+ADD COLUMN quoted_chirp_id UUID;
+
+CREATE INDEX chirps_quoted_chirp_id_idx ON chirps(quoted_chirp_id);
+
+-- +goose Down
+DROP INDEX chirps_quoted_chirp_id_idx;
+
+ALTER TABLE chirps
+DROP COLUMN quoted_chirp_id;
+
+DROP TABLE rechirps;
@@ -0,0 +1,24 @@
+-- +goose Up
+CREATE TABLE notifications(
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type TEXT NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL,
+    read_at TIMESTAMP
+);
+
+CREATE INDEX notifications_user_id_idx ON notifications(user_id, created_at, id);
+CREATE INDEX notifications_unread_idx ON notifications(user_id) WHERE read_at IS NULL;
+
+CREATE TABLE login_devices(
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    device_hash TEXT NOT NULL,
+    first_seen_at TIMESTAMP NOT NULL,
+    last_seen_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, device_hash)
+);
+
+-- +goose Down
+DROP TABLE login_devices;
+DROP TABLE notifications;
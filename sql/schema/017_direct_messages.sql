@@ -0,0 +1,32 @@
+-- +goose Up
+CREATE TABLE conversations(
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE conversation_participants(
+    conversation_id UUID NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    joined_at TIMESTAMP NOT NULL,
+    last_read_at TIMESTAMP,
+    deleted_at TIMESTAMP,
+    PRIMARY KEY (conversation_id, user_id)
+);
+
+CREATE INDEX conversation_participants_user_id_idx ON conversation_participants(user_id);
+
+CREATE TABLE messages(
+    id UUID PRIMARY KEY,
+    conversation_id UUID NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    sender_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX messages_conversation_id_idx ON messages(conversation_id, created_at, id);
+
+-- +goose Down
+DROP TABLE messages;
+DROP TABLE conversation_participants;
+DROP TABLE conversations;
@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE media_attachments(
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    chirp_id UUID REFERENCES chirps(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL DEFAULT 0,
+    content_type TEXT NOT NULL,
+    storage_key TEXT NOT NULL,
+    thumbnail_key TEXT NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    thumbnail_width INTEGER NOT NULL,
+    thumbnail_height INTEGER NOT NULL,
+    size_bytes INTEGER NOT NULL,
+    alt_text TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX media_attachments_chirp_id_idx ON media_attachments(chirp_id, position);
+
+-- +goose Down
+DROP TABLE media_attachments;
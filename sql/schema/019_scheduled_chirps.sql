@@ -0,0 +1,23 @@
+-- +goose Up
+CREATE TABLE scheduled_chirps(
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    publish_at TIMESTAMP NOT NULL,
+    body TEXT NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    parent_id UUID,
+    quoted_chirp_id UUID
+);
+
+CREATE INDEX scheduled_chirps_publish_at_idx ON scheduled_chirps(publish_at);
+CREATE INDEX scheduled_chirps_user_id_idx ON scheduled_chirps(user_id, publish_at, id);
+
+ALTER TABLE media_attachments
+ADD COLUMN scheduled_chirp_id UUID REFERENCES scheduled_chirps(id) ON DELETE SET NULL;
+
+-- +goose Down
+ALTER TABLE media_attachments
+DROP COLUMN scheduled_chirp_id;
+
+DROP TABLE scheduled_chirps;
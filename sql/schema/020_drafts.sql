@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE drafts(
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    parent_id UUID,
+    quoted_chirp_id UUID,
+    version INTEGER NOT NULL
+);
+
+CREATE INDEX drafts_user_id_idx ON drafts(user_id, updated_at, id);
+
+-- +goose Down
+DROP TABLE drafts;
@@ -0,0 +1,22 @@
+-- +goose Up
+ALTER TABLE chirps
+ADD COLUMN visibility TEXT NOT NULL DEFAULT 'public'
+    CHECK (visibility IN ('public', 'unlisted', 'private'));
+
+ALTER TABLE scheduled_chirps
+ADD COLUMN visibility TEXT NOT NULL DEFAULT 'public'
+    CHECK (visibility IN ('public', 'unlisted', 'private'));
+
+ALTER TABLE drafts
+ADD COLUMN visibility TEXT NOT NULL DEFAULT 'public'
+    CHECK (visibility IN ('public', 'unlisted', 'private'));
+
+-- +goose Down
+ALTER TABLE drafts
+DROP COLUMN visibility;
+
+ALTER TABLE scheduled_chirps
+DROP COLUMN visibility;
+
+ALTER TABLE chirps
+DROP COLUMN visibility;
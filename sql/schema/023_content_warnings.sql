@@ -0,0 +1,35 @@
+-- +goose Up
+ALTER TABLE chirps
+ADD COLUMN content_warning TEXT NOT NULL DEFAULT '',
+ADD COLUMN sensitive BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE scheduled_chirps
+ADD COLUMN content_warning TEXT NOT NULL DEFAULT '',
+ADD COLUMN sensitive BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE drafts
+ADD COLUMN content_warning TEXT NOT NULL DEFAULT '',
+ADD COLUMN sensitive BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE users
+ADD COLUMN sensitive_content TEXT NOT NULL DEFAULT 'withhold'
+    CHECK (sensitive_content IN ('expand', 'hide', 'withhold')),
+ADD COLUMN role TEXT NOT NULL DEFAULT 'user'
+    CHECK (role IN ('user', 'moderator', 'admin'));
+
+-- +goose Down
+ALTER TABLE users
+DROP COLUMN role,
+DROP COLUMN sensitive_content;
+
+ALTER TABLE drafts
+DROP COLUMN sensitive,
+DROP COLUMN content_warning;
+
+ALTER TABLE scheduled_chirps
+DROP COLUMN sensitive,
+DROP COLUMN content_warning;
+
+ALTER TABLE chirps
+DROP COLUMN sensitive,
+DROP COLUMN content_warning;
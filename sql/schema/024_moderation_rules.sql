@@ -0,0 +1,28 @@
+-- +goose Up
+CREATE TABLE moderation_rules (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('word', 'regex')),
+    pattern TEXT NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('mask', 'reject', 'hold'))
+);
+
+INSERT INTO moderation_rules(id, created_at, updated_at, kind, pattern, action)
+VALUES
+    (gen_random_uuid(), NOW(), NOW(), 'word', 'kerfuffle', 'mask'),
+    (gen_random_uuid(), NOW(), NOW(), 'word', 'sharbert', 'mask'),
+    (gen_random_uuid(), NOW(), NOW(), 'word', 'fornax', 'mask');
+
+ALTER TABLE chirps
+ADD COLUMN held_for_review BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX chirps_held_for_review_idx ON chirps(created_at, id) WHERE held_for_review;
+
+-- +goose Down
+DROP INDEX chirps_held_for_review_idx;
+
+ALTER TABLE chirps
+DROP COLUMN held_for_review;
+
+DROP TABLE moderation_rules;
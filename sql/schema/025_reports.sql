@@ -0,0 +1,53 @@
+-- +goose Up
+CREATE TABLE report_cases (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    -- not a foreign key, so the case still records which chirp it was
+    -- about after the chirp is deleted
+    chirp_id UUID,
+    reported_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'open'
+        CHECK (status IN ('open', 'claimed', 'resolved')),
+    claimed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    resolution TEXT NOT NULL DEFAULT ''
+        CHECK (resolution IN ('', 'removed', 'warned', 'dismissed')),
+    resolution_note TEXT NOT NULL DEFAULT '',
+    resolved_at TIMESTAMP
+);
+
+-- one unresolved case per chirp, and per account for reports that aren't
+-- about a particular chirp
+CREATE UNIQUE INDEX report_cases_open_chirp_idx ON report_cases(chirp_id)
+    WHERE chirp_id IS NOT NULL AND status <> 'resolved';
+CREATE UNIQUE INDEX report_cases_open_user_idx ON report_cases(reported_user_id)
+    WHERE chirp_id IS NULL AND status <> 'resolved';
+CREATE INDEX report_cases_status_created_at_id_idx ON report_cases(status, created_at, id);
+
+CREATE TABLE reports (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    case_id UUID NOT NULL REFERENCES report_cases(id) ON DELETE CASCADE,
+    reporter_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL
+        CHECK (reason IN ('spam', 'harassment', 'hate', 'violence', 'sexual', 'misinformation', 'other')),
+    comment TEXT NOT NULL,
+    UNIQUE (case_id, reporter_id)
+);
+
+CREATE TABLE report_case_events (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    case_id UUID NOT NULL REFERENCES report_cases(id) ON DELETE CASCADE,
+    actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    action TEXT NOT NULL
+        CHECK (action IN ('reported', 'claimed', 'released', 'removed', 'warned', 'dismissed')),
+    note TEXT NOT NULL
+);
+
+CREATE INDEX report_case_events_case_id_idx ON report_case_events(case_id, created_at);
+
+-- +goose Down
+DROP TABLE report_case_events;
+DROP TABLE reports;
+DROP TABLE report_cases;
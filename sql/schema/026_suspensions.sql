@@ -0,0 +1,15 @@
+-- +goose Up
+-- a user is suspended while suspended_at is set and suspended_until is
+-- either in the future or NULL, for a permanent ban
+ALTER TABLE users
+ADD COLUMN suspended_at TIMESTAMP,
+ADD COLUMN suspended_until TIMESTAMP,
+ADD COLUMN suspension_reason TEXT NOT NULL DEFAULT '',
+ADD COLUMN suspension_hides_chirps BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE users
+DROP COLUMN suspension_hides_chirps,
+DROP COLUMN suspension_reason,
+DROP COLUMN suspended_until,
+DROP COLUMN suspended_at;
@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE chirp_fingerprints (
+    chirp_id UUID PRIMARY KEY REFERENCES chirps(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    content_hash TEXT NOT NULL,
+    shingles BIGINT[] NOT NULL,
+    links TEXT[] NOT NULL
+);
+
+CREATE INDEX chirp_fingerprints_created_at_idx ON chirp_fingerprints(created_at);
+CREATE INDEX chirp_fingerprints_user_id_idx ON chirp_fingerprints(user_id, created_at);
+CREATE INDEX chirp_fingerprints_content_hash_idx ON chirp_fingerprints(content_hash, created_at);
+CREATE INDEX chirp_fingerprints_shingles_idx ON chirp_fingerprints USING GIN (shingles);
+CREATE INDEX chirp_fingerprints_links_idx ON chirp_fingerprints USING GIN (links);
+
+-- +goose Down
+DROP TABLE chirp_fingerprints;
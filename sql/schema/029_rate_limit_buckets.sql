@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE rate_limit_buckets (
+    key TEXT PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    full_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX rate_limit_buckets_full_at_idx ON rate_limit_buckets(full_at);
+
+-- +goose Down
+DROP TABLE rate_limit_buckets;
@@ -0,0 +1,15 @@
+-- +goose Up
+ALTER TABLE chirps
+ADD COLUMN deleted_at TIMESTAMP,
+ADD COLUMN deleted_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX chirps_deleted_at_idx ON chirps(deleted_at, id) WHERE deleted_at IS NOT NULL;
+CREATE INDEX chirps_trash_idx ON chirps(user_id, deleted_at, id) WHERE deleted_at IS NOT NULL;
+
+-- +goose Down
+DROP INDEX chirps_trash_idx;
+DROP INDEX chirps_deleted_at_idx;
+
+ALTER TABLE chirps
+DROP COLUMN deleted_by,
+DROP COLUMN deleted_at;
@@ -0,0 +1,50 @@
+-- +goose Up
+-- +goose StatementBegin
+-- user_visible_to decides whether a viewer, who may be NULL for anonymous
+-- readers, gets to see what a user does: their chirps, rechirps, likes and
+-- votes. Everyone sees their own. Nobody else sees shadow-banned users,
+-- suspended users whose chirps are hidden, or users on the other side of a
+-- block. Muted users are only left out of what's listed.
+CREATE FUNCTION user_visible_to(user_id UUID, viewer_id UUID, listed BOOLEAN) RETURNS BOOLEAN
+LANGUAGE sql STABLE AS $$
+    SELECT (user_id = viewer_id) IS TRUE
+        OR (NOT EXISTS (SELECT 1 FROM users
+                WHERE users.id = user_id
+                    AND (users.shadow_banned
+                        OR (users.suspension_hides_chirps
+                            AND (users.suspended_until IS NULL OR users.suspended_until > NOW()))))
+            AND NOT EXISTS (SELECT 1 FROM blocks
+                WHERE (blocks.blocker_id = user_id AND blocks.blocked_id = viewer_id)
+                    OR (blocks.blocker_id = viewer_id AND blocks.blocked_id = user_id))
+            AND (NOT listed OR NOT EXISTS (SELECT 1 FROM mutes
+                WHERE mutes.muter_id = viewer_id AND mutes.muted_id = user_id)))
+$$;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+-- chirp_visible_to decides whether a viewer gets to see a chirp, so every
+-- read query applies the same rules. Authors always see their own chirps.
+-- Everyone else sees neither private nor held chirps, nor chirps by users
+-- hidden from them. Listed chirps are the ones that go in lists, searches
+-- and timelines: only public ones, not muted users' ones, and not flagged
+-- ones if the viewer hides sensitive content. Chirps opened directly, quoted, or in a thread aren't
+-- listed, and flagged ones come back withheld instead.
+CREATE FUNCTION chirp_visible_to(chirp_id UUID, viewer_id UUID, listed BOOLEAN) RETURNS BOOLEAN
+LANGUAGE sql STABLE AS $$
+    SELECT (chirps.user_id = viewer_id) IS TRUE
+        OR (NOT chirps.held_for_review
+            AND chirps.visibility <> 'private'
+            AND (NOT listed OR chirps.visibility = 'public')
+            AND user_visible_to(chirps.user_id, viewer_id, listed)
+            AND (NOT listed
+                OR NOT (chirps.sensitive OR chirps.content_warning <> '')
+                OR NOT EXISTS (SELECT 1 FROM users
+                    WHERE users.id = viewer_id AND users.sensitive_content = 'hide')))
+    FROM chirps
+    WHERE chirps.id = chirp_id
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP FUNCTION chirp_visible_to(UUID, UUID, BOOLEAN);
+DROP FUNCTION user_visible_to(UUID, UUID, BOOLEAN);
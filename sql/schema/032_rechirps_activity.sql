@@ -0,0 +1,5 @@
+-- +goose Up
+CREATE INDEX rechirps_user_id_created_at_idx ON rechirps(user_id, created_at, chirp_id);
+
+-- +goose Down
+DROP INDEX rechirps_user_id_created_at_idx;
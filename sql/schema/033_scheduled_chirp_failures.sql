@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE scheduled_chirps
+ADD COLUMN failed_attempts INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN last_failed_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE scheduled_chirps
+DROP COLUMN last_failed_at,
+DROP COLUMN failed_attempts;
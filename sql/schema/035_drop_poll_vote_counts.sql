@@ -0,0 +1,10 @@
+-- +goose Up
+ALTER TABLE poll_options
+DROP COLUMN vote_count;
+
+-- +goose Down
+ALTER TABLE poll_options
+ADD COLUMN vote_count INTEGER NOT NULL DEFAULT 0;
+
+UPDATE poll_options
+SET vote_count = (SELECT COUNT(*) FROM poll_votes WHERE poll_votes.option_id = poll_options.id);
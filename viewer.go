@@ -0,0 +1,29 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/philipreese/chirpy-go/internal/auth"
+)
+
+// getViewerID returns the ID of the user making a request to an endpoint
+// that can be read anonymously. Requests without an Authorization header
+// have no viewer, but a token that is sent has to be valid.
+func (cfg *apiConfig) getViewerID(req *http.Request) (uuid.NullUUID, error) {
+	if req.Header.Get("Authorization") == "" {
+		return uuid.NullUUID{}, nil
+	}
+
+	tokenString, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		return uuid.NullUUID{}, err
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err != nil {
+		return uuid.NullUUID{}, err
+	}
+
+	return uuid.NullUUID{UUID: userID, Valid: true}, nil
+}